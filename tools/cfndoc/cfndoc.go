@@ -55,6 +55,19 @@ Resources:
       RedrivePolicy:
         deadLetterTargetArn: !GetAtt DeadLetterQueue.Arn
         maxReceiveCount: 10
+
+Resources named at deploy time (e.g., with a !Ref to a parameter) are documented by their logical id, which is the
+label when the tags immediately follow it.
+
+Example:
+Resources:
+  WebApplicationServer:
+    # <cfndoc>
+    # The ECS service of the Panther UI.
+    # </cfndoc>
+    Type: AWS::ECS::Service
+    Properties:
+      ServiceName: !Ref ServiceName
 */
 
 const (
@@ -64,7 +77,8 @@ const (
 
 var (
 	commentMarkers      = regexp.MustCompile(`\n\s*[#]`)
-	extractResourceDocs = regexp.MustCompile(`(?s)[[:alpha:]]+:[\s]*([\S^]+)[\s\#]*` + StartTag + `(.+?)` + EndTag)
+	extractResourceDocs = regexp.MustCompile(`(?s)(?:[[:alpha:]]+:[ \t]*([^\s#]+)|([[:alpha:]][[:alnum:]]*):)[\s\#]*` +
+		StartTag + `(.+?)` + EndTag)
 )
 
 type ResourceDoc struct {
@@ -113,12 +127,16 @@ func Read(fileName string) (docs []*ResourceDoc, err error) {
 
 func Parse(cfn string) (docs []*ResourceDoc) {
 	for _, match := range extractResourceDocs.FindAllStringSubmatch(cfn, -1) {
-		if len(match) != 3 {
+		if len(match) != 4 {
 			panic(fmt.Sprintf("bad match, likely regexp is wrong: %#v", match))
 		}
+		resource := match[1]
+		if resource == "" { // documented by logical id
+			resource = match[2]
+		}
 		docs = append(docs, &ResourceDoc{
-			Resource:      match[1],
-			Documentation: clean(match[2]),
+			Resource:      resource,
+			Documentation: clean(match[3]),
 		})
 	}
 	return docs
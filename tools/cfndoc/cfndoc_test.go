@@ -68,6 +68,19 @@ Resource: label
 #
 # </cfndoc>
 
+`
+	require.Equal(t, expected, Parse(match))
+
+	// the logical id when the tags immediately follow it
+	match = `
+Resources:
+  label:
+    # <cfndoc>
+    # doc doc
+    # </cfndoc>
+    Type: AWS::ECS::Service
+    Properties:
+      ServiceName: !Ref ServiceName
 `
 	require.Equal(t, expected, Parse(match))
 
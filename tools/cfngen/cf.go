@@ -25,6 +25,7 @@ import (
 	"regexp"
 
 	jsoniter "github.com/json-iterator/go"
+	"gopkg.in/yaml.v2"
 )
 
 // FIXME: consider replacing this with CDK when a Go version is available.
@@ -87,6 +88,32 @@ func (t *Template) CloudFormation() ([]byte, error) {
 	return buffer.Bytes(), err
 }
 
+// Emit CF as YAML to io.Writer, map keys are sorted and block style is used so output is stable for diffs
+func (t *Template) WriteCloudFormationYAML(w io.Writer) (err error) {
+	// go through JSON so the json tags used for CF names are honored
+	jsonBytes, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	var yamlObj interface{}
+	if err = yaml.Unmarshal(jsonBytes, &yamlObj); err != nil {
+		return err
+	}
+	yamlBytes, err := yaml.Marshal(yamlObj)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(yamlBytes)
+	return
+}
+
+// Emit CF as YAML return []bytes
+func (t *Template) CloudFormationYAML() ([]byte, error) {
+	buffer := bytes.Buffer{}
+	err := t.WriteCloudFormationYAML(&buffer)
+	return buffer.Bytes(), err
+}
+
 // Create a CF template , use WriteCloudFormation() to emit.
 func NewTemplate(description string, parameters map[string]interface{}, resources map[string]interface{},
 	outputs map[string]interface{}) (t *Template) {
@@ -0,0 +1,88 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// the alarm state transitions an action fires on
+const (
+	ActionOnAlarm = "ALARM" // the default
+	ActionOnOK    = "OK"
+	ActionOnBoth  = "BOTH"
+)
+
+// AlarmAction triggers an action (e.g., a remediation Lambda or SSM Automation) for matching alarms, in addition to
+// the notification topic. An alarm matches if it is for the resource LogicalID and the MetricName, an empty field
+// matches any alarm.
+type AlarmAction struct {
+	LogicalID  string      `json:"logicalId,omitempty" yaml:"logicalId,omitempty"`
+	MetricName string      `json:"metricName,omitempty" yaml:"metricName,omitempty"`
+	Action     interface{} `json:"action" yaml:"action"`                   // arn or a CF intrinsic (e.g., Fn::GetAtt of a Lambda in the stack)
+	On         string      `json:"state,omitempty" yaml:"state,omitempty"` // ActionOnAlarm, ActionOnOK or ActionOnBoth, "" is ActionOnAlarm
+}
+
+func (action *AlarmAction) matches(alarm *Alarm) bool {
+	return (action.LogicalID == "" || action.LogicalID == alarm.LogicalID) &&
+		(action.MetricName == "" || action.MetricName == alarm.Properties.MetricName)
+}
+
+// AlarmActions are added in order after the notification topic, so the actions of an alarm are deterministic
+type AlarmActions []AlarmAction
+
+// apply adds the actions of all matching AlarmActions to the alarm
+func (actions AlarmActions) apply(alarm *Alarm) error {
+	for i := range actions {
+		if !actions[i].matches(alarm) {
+			continue
+		}
+		onAlarm, onOK := false, false
+		switch actions[i].On {
+		case "", ActionOnAlarm:
+			onAlarm = true
+		case ActionOnOK:
+			onOK = true
+		case ActionOnBoth:
+			onAlarm, onOK = true, true
+		default:
+			return errors.Errorf("alarm %s: action on %s is not one of %s, %s or %s", alarm.Properties.AlarmName,
+				actions[i].On, ActionOnAlarm, ActionOnOK, ActionOnBoth)
+		}
+		if onAlarm {
+			alarm.Properties.AlarmActions = appendAction(alarm.Properties.AlarmActions, actions[i].Action)
+		}
+		if onOK {
+			alarm.Properties.OKActions = appendAction(alarm.Properties.OKActions, actions[i].Action)
+		}
+	}
+	return nil
+}
+
+// appendAction adds the action unless it is already one of the actions
+func appendAction(actions []interface{}, action interface{}) []interface{} {
+	for _, existing := range actions {
+		if reflect.DeepEqual(existing, action) {
+			return actions
+		}
+	}
+	return append(actions, action)
+}
@@ -0,0 +1,69 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAlarmsActions(t *testing.T) {
+	remediation := map[string]interface{}{"Fn::GetAtt": []interface{}{"RemediationFunction", "Arn"}}
+	const automation = "arn:aws:ssm:us-east-1:123456789012:automation-definition/RestartService"
+	config := NewConfig("my-sns-topic-arn", nil).Actions(
+		AlarmAction{LogicalID: "AlertsTopic", MetricName: "NumberOfNotificationsFailed", Action: remediation},
+		AlarmAction{MetricName: "NumberOfNotificationsFailed", Action: automation, On: ActionOnBoth},
+	)
+	alarms, cf, err := GenerateAlarmsWithConfig(config, "./testdata/sns.yml")
+	require.NoError(t, err)
+
+	var remediated int
+	for _, alarm := range alarms {
+		switch {
+		case alarm.LogicalID == "AlertsTopic" && alarm.Properties.MetricName == "NumberOfNotificationsFailed":
+			remediated++
+			// the notification topic first then the actions in order
+			require.Equal(t, []interface{}{"my-sns-topic-arn", remediation, automation}, alarm.Properties.AlarmActions)
+			require.Equal(t, []interface{}{automation}, alarm.Properties.OKActions)
+		case alarm.Properties.MetricName == "NumberOfNotificationsFailed":
+			require.Equal(t, []interface{}{"my-sns-topic-arn", automation}, alarm.Properties.AlarmActions)
+			require.Equal(t, []interface{}{automation}, alarm.Properties.OKActions)
+		default:
+			require.Equal(t, []interface{}{"my-sns-topic-arn"}, alarm.Properties.AlarmActions)
+			require.Empty(t, alarm.Properties.OKActions)
+		}
+	}
+	require.Equal(t, 1, remediated)
+	// rendered as an array in the same order
+	var template struct {
+		Resources map[string]struct {
+			Properties struct {
+				AlarmActions []interface{}
+			}
+		}
+	}
+	require.NoError(t, json.Unmarshal(cf, &template))
+	require.Equal(t, []interface{}{"my-sns-topic-arn", remediation, automation},
+		template.Resources["PantherAlarmSNSErrortestalertsAlertsTopicNumberOfNotificationsFailedSum"].Properties.AlarmActions)
+
+	_, _, err = GenerateAlarmsWithConfig(NewConfig("my-sns-topic-arn", nil).Actions(
+		AlarmAction{Action: automation, On: "INSUFFICIENT_DATA"}), "./testdata/sns.yml")
+	require.Error(t, err)
+}
@@ -0,0 +1,49 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"path"
+)
+
+// DisableActions configures alarms to be deployed with their actions disabled, so they evaluate and show their state
+// without notifying (e.g., to tune the thresholds of new alarms against real traffic before they page). Only alarms
+// with names matching one of the globs (see path.Match) are disabled, all the alarms if there are none.
+func (config *Config) DisableActions(alarmNameGlobs ...string) *Config {
+	config.disableActions = true
+	config.disableActionsGlobs = alarmNameGlobs
+	return config
+}
+
+// disableActions sets ActionsEnabled false if the alarm is configured to be disabled, it is omitted when enabled
+func (alarm *Alarm) disableActions(config *Config) {
+	if !config.disableActions {
+		return
+	}
+	matches := len(config.disableActionsGlobs) == 0
+	for _, glob := range config.disableActionsGlobs {
+		if matched, _ := path.Match(glob, alarm.Properties.AlarmName); matched { // a malformed glob matches nothing
+			matches = true
+			break
+		}
+	}
+	if matches {
+		actionsEnabled := false
+		alarm.Properties.ActionsEnabled = &actionsEnabled
+	}
+}
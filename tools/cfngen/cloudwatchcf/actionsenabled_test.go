@@ -0,0 +1,76 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisableActions(t *testing.T) {
+	actionsEnabled := func(cf []byte) map[string]interface{} {
+		var template struct {
+			Resources map[string]struct {
+				Properties map[string]interface{}
+			}
+		}
+		require.NoError(t, json.Unmarshal(cf, &template))
+		enabled := make(map[string]interface{})
+		for _, resource := range template.Resources {
+			name := resource.Properties["AlarmName"].(string)
+			if value, found := resource.Properties["ActionsEnabled"]; found {
+				enabled[name] = value
+			}
+		}
+		return enabled
+	}
+
+	// enabled is the default, not emitted
+	_, cf, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/firehose.yml")
+	require.NoError(t, err)
+	require.Empty(t, actionsEnabled(cf))
+
+	alarms, cf, err := GenerateAlarmsWithConfig(NewConfig("my-sns-topic-arn", nil).DisableActions(),
+		"./testdata/firehose.yml")
+	require.NoError(t, err)
+	requireValidCloudFormation(t, cf)
+	require.Len(t, actionsEnabled(cf), len(alarms))
+	for name, enabled := range actionsEnabled(cf) {
+		require.Equal(t, false, enabled, name)
+	}
+	// the alarms still notify once enabled
+	require.NotEmpty(t, alarms[0].Properties.AlarmActions)
+	require.Contains(t, string(TerraformAlarms(alarms)), "actions_enabled     = false")
+
+	// only the matching alarms
+	alarms, cf, err = GenerateAlarmsWithConfig(NewConfig("my-sns-topic-arn", nil).
+		DisableActions("PantherAlarm-FirehoseThrottles-*", "PantherAlarm-NoSuchAlarm-*"), "./testdata/firehose.yml")
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"PantherAlarm-FirehoseThrottles-panther-log-delivery-LogDeliveryStream-ThrottledRecords-Sum": false,
+	}, actionsEnabled(cf))
+
+	// a composite alarm of a disabled alarm is disabled, it would otherwise notify for it
+	compositeAlarms, cf, err := GenerateCompositeAlarms(alarms)
+	require.NoError(t, err)
+	requireValidCloudFormation(t, cf)
+	require.Len(t, compositeAlarms, 1)
+	require.NotNil(t, compositeAlarms[0].Properties.ActionsEnabled)
+	require.False(t, *compositeAlarms[0].Properties.ActionsEnabled)
+}
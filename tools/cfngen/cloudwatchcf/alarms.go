@@ -19,47 +19,242 @@ package cloudwatchcf
  */
 
 import (
-	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
 
-	"github.com/panther-labs/panther/tools/cfngen"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/pkg/errors"
 )
 
 const (
 	documentationURL = "https://docs.runpanther.io/operations/runbooks" // where all alarms are documented
 	alarmPrefix      = "PantherAlarm"
+
+	maxResourceNameLength = 255 // the limit of CF logical ids
+)
+
+// how alarms treat missing data points, see:
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/AlarmThatSendsEmail.html#alarms-and-missing-data
+const (
+	TreatMissingDataNotBreaching = "notBreaching" // default for threshold alarms so zero traffic is OK
+	TreatMissingDataBreaching    = "breaching"
+	TreatMissingDataIgnore       = "ignore"
+	TreatMissingDataMissing      = "missing" // the CloudWatch default, not emitted
 )
 
 type Alarm struct {
-	Resource   string `json:"-"` // use '-' tag so field is not serialized
-	Type       string
-	Properties AlarmProperties
+	Resource    string  `json:"-"` // use '-' tag so field is not serialized
+	LogicalID   string  `json:"-"` // logical id of the monitored resource in the CF
+	AnomalyBand float64 `json:"-"` // if > 0, alarm on an anomaly detection band this many standard deviations wide
+	Type        string
+	DependsOn   []string `json:",omitempty"` // generated resources producing the metrics of the alarm
+	Properties  AlarmProperties
+
+	templateFile     string           // of the monitored resource, logical ids are unique within a template
+	resourceType     string           // of the monitored resource
+	anomalyDetector  *AnomalyDetector // created when the alarm is rendered for anomaly detection
+	namePrefix       string           // configured prefix of the name, composite alarms of the alarms share it
+	buildErr         error            // from configuring the alarm, fails the generation
+	metricMath       *metricMath      // replaces the metric when rendered, nil for none
+	maintenanceAlarm *CompositeAlarm  // has the actions while suppressed during maintenance, nil if not suppressed
 }
 
 // see: https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-cw-alarm.html
 type AlarmProperties struct {
-	AlarmName          string
-	AlarmDescription   string   `json:",omitempty"`
-	AlarmActions       []string `json:",omitempty"`
-	TreatMissingData   string   `json:",omitempty"`
-	Namespace          string   `json:",omitempty"`
-	MetricName         string
-	Dimensions         []MetricDimension `json:",omitempty"`
-	ComparisonOperator string
-	EvaluationPeriods  int
-	Period             int
-	Threshold          float32
-	Unit               string
-	Statistic          string
+	AlarmName               string
+	AlarmDescription        string            `json:",omitempty"`
+	ActionsEnabled          *bool             `json:",omitempty"` // nil is enabled, the CloudWatch default
+	AlarmActions            []interface{}     `json:",omitempty"`
+	OKActions               []interface{}     `json:",omitempty"`
+	InsufficientDataActions []interface{}     `json:",omitempty"`
+	TreatMissingData        string            `json:",omitempty"` // "" is TreatMissingDataMissing
+	Namespace               string            `json:",omitempty"`
+	MetricName              string            `json:",omitempty"`
+	Dimensions              []MetricDimension `json:",omitempty"`
+	Metrics                 []MetricDataQuery `json:",omitempty"`
+	ComparisonOperator      string
+	EvaluationPeriods       int
+	DatapointsToAlarm       int      `json:",omitempty"` // "M" of "M out of N" periods, 0 is EvaluationPeriods
+	Period                  int      `json:",omitempty"`
+	Threshold               *float32 `json:",omitempty"`
+	ThresholdMetricID       string   `json:"ThresholdMetricId,omitempty"`
+	Unit                    string   `json:",omitempty"`
+	Statistic               string   `json:",omitempty"`
+	ExtendedStatistic       string   `json:",omitempty"` // exclusive of Statistic
+	Tags                    []Tag    `json:",omitempty"`
 }
 
 type MetricDimension struct {
 	Name  string
-	Value string
+	Value interface{} // a string or a CF intrinsic (e.g., Ref) resolved at deploy time
 }
 
 type Config struct {
 	snsTopicArn  string            // where to send alarms
 	stackOutputs map[string]string // used to lookup dynamically configured references created previously
+
+	kinesisIteratorAgeThreshold float32                 // msec a Kinesis stream consumer may fall behind
+	overrides                   Overrides               // replace default alarm settings per resource and metric
+	routes                      AlarmRoutes             // send alarms to topics other than snsTopicArn per resource
+	actions                     AlarmActions            // triggered in addition to the notifications (e.g., remediation)
+	followNestedStacks          bool                    // generate alarms for nested stacks with local templates
+	format                      OutputFormat            // serialization of the generated CF
+	lambdaDurationStatistic     string                  // extended statistic for Lambda duration alarms, "" is Maximum
+	lambdaErrorPattern          string                  // log filter pattern for Lambda application errors, "" is by runtime
+	lambdaErrorPatterns         map[string]string       // per Lambda logical id, replaces lambdaErrorPattern
+	lambdaQueryDefinitions      bool                    // generate Logs Insights queries for Lambda log groups with the alarms
+	lambdaQuery                 string                  // of the Logs Insights queries, "" is DefaultLambdaQuery
+	apiClientErrorAlarms        bool                    // alarm on 4XX errors of API Gateway APIs
+	lambdaErrorRateThreshold    float32                 // % of Lambda invocations failing, 0 for no error rate alarms
+	exclusions                  *Exclusions             // resources to skip
+	descriptionTemplate         *template.Template      // renders AlarmDescription, nil keeps the default for the alarm type
+	environment                 string                  // folded into alarm names, "" for none
+	profile                     *Profile                // settings for the environment, nil for none
+	highResolutionMetrics       map[string]struct{}     // by namespace/metric name, these may use 10 or 30 sec periods
+	tags                        map[string]string       // applied to the generated alarms
+	prefix                      string                  // of alarm and dashboard names and the metric namespace, "" for none
+	disableActions              bool                    // deploy alarms with ActionsEnabled false
+	disableActionsGlobs         []string                // alarm names limiting disableActions, nil for all alarms
+	customAlarms                []CustomAlarm           // on metrics not derived from the resources
+	lambdaConcurrencyLimit      float32                 // of the account, 0 for no account concurrency alarm
+	maintenance                 *MaintenanceSuppression // alarms suppressed while the maintenance alarm is in ALARM
+	scope                       map[string]struct{}     // logical ids of the only resources alarmed, nil for all
+}
+
+func NewConfig(snsTopicArn string, stackOutputs map[string]string) *Config {
+	return &Config{
+		snsTopicArn:                 snsTopicArn,
+		stackOutputs:                stackOutputs,
+		kinesisIteratorAgeThreshold: defaultKinesisIteratorAgeThreshold,
+	}
+}
+
+// Overrides configures alarm settings that replace the defaults for specific resources and metrics
+func (config *Config) Overrides(overrides Overrides) *Config {
+	config.overrides = overrides
+	return config
+}
+
+// Routes configures the topics alarms are sent to for specific resources, unmatched resources use the default topic
+func (config *Config) Routes(routes ...AlarmRoute) *Config {
+	config.routes = routes
+	return config
+}
+
+// Actions configures actions (e.g., remediation Lambdas) triggered by matching alarms in addition to the notifications
+func (config *Config) Actions(actions ...AlarmAction) *Config {
+	config.actions = actions
+	return config
+}
+
+// FollowNestedStacks configures generating alarms for the resources of nested stacks whose TemplateURL is a local file
+func (config *Config) FollowNestedStacks(follow bool) *Config {
+	config.followNestedStacks = follow
+	return config
+}
+
+// Format configures the serialization of the generated CF, the default is JSON
+func (config *Config) Format(format OutputFormat) *Config {
+	config.format = format
+	return config
+}
+
+// LambdaDurationStatistic configures an extended statistic (e.g., p99) for Lambda duration alarms to catch tail latency
+func (config *Config) LambdaDurationStatistic(statistic string) *Config {
+	config.lambdaDurationStatistic = statistic
+	return config
+}
+
+// LambdaErrorPattern configures the log filter pattern (e.g., { $.level = "error" }) used to count Lambda application
+// errors, replacing the default for the runtime
+func (config *Config) LambdaErrorPattern(pattern string) *Config {
+	config.lambdaErrorPattern = pattern
+	return config
+}
+
+// LambdaErrorPatternFor configures the log filter pattern used to count application errors for the Lambda logicalID
+func (config *Config) LambdaErrorPatternFor(logicalID, pattern string) *Config {
+	if config.lambdaErrorPatterns == nil {
+		config.lambdaErrorPatterns = make(map[string]string)
+	}
+	config.lambdaErrorPatterns[logicalID] = pattern
+	return config
+}
+
+func (config *Config) lambdaErrorPatternFor(logicalID string) string {
+	if pattern, found := config.lambdaErrorPatterns[logicalID]; found {
+		return pattern
+	}
+	return config.lambdaErrorPattern
+}
+
+// LambdaQueryDefinitions configures generating a saved Logs Insights query for the log group of each Lambda with the
+// alarms, to investigate when an alarm fires
+func (config *Config) LambdaQueryDefinitions(enable bool) *Config {
+	config.lambdaQueryDefinitions = enable
+	return config
+}
+
+// LambdaQuery configures the Logs Insights query saved for each Lambda, replacing DefaultLambdaQuery
+func (config *Config) LambdaQuery(query string) *Config {
+	config.lambdaQuery = query
+	return config
+}
+
+// LambdaErrorRateThreshold configures alarming when more than the percent of the invocations of a Lambda fail, in
+// addition to alarming on any errors, off by default
+func (config *Config) LambdaErrorRateThreshold(percent float32) *Config {
+	config.lambdaErrorRateThreshold = percent
+	return config
+}
+
+// APIClientErrorAlarms configures alarming on client (4XX) errors of API Gateway REST and HTTP APIs, off by default
+func (config *Config) APIClientErrorAlarms(enable bool) *Config {
+	config.apiClientErrorAlarms = enable
+	return config
+}
+
+// Exclude configures resources to skip, they will have no metric filters, alarms or dashboard widgets
+func (config *Config) Exclude(exclusions *Exclusions) *Config {
+	config.exclusions = exclusions
+	return config
+}
+
+// AlarmDescriptionTemplate configures the template rendering the AlarmDescription of each alarm,
+// see ParseAlarmDescriptionTemplate
+func (config *Config) AlarmDescriptionTemplate(descriptionTemplate *template.Template) *Config {
+	config.descriptionTemplate = descriptionTemplate
+	return config
+}
+
+// Environment configures generating the alarms for the environment (e.g., dev) with the settings of its profile,
+// environments without a profile use the DefaultProfile if there is one
+func (config *Config) Environment(environment string, profiles Profiles) *Config {
+	config.environment = environment
+	config.profile = profiles.profile(environment)
+	return config
+}
+
+// HighResolutionMetrics configures the metrics (e.g., Panther/queue-age) published with a 1 sec storage resolution,
+// alarms on these may use 10 or 30 sec periods
+func (config *Config) HighResolutionMetrics(metrics ...string) *Config {
+	config.highResolutionMetrics = make(map[string]struct{}, len(metrics))
+	for _, metric := range metrics {
+		config.highResolutionMetrics[metric] = struct{}{}
+	}
+	return config
+}
+
+// KinesisIteratorAgeThreshold configures how many msec Kinesis stream consumers may fall behind before alarming
+func (config *Config) KinesisIteratorAgeThreshold(threshold float32) *Config {
+	config.kinesisIteratorAgeThreshold = threshold
+	return config
 }
 
 func NewAlarm(resource, name, description, snsTopicArn string) (alarm *Alarm) {
@@ -73,7 +268,7 @@ func NewAlarm(resource, name, description, snsTopicArn string) (alarm *Alarm) {
 		},
 	}
 	if snsTopicArn != "" {
-		alarm.Properties.AlarmActions = []string{snsTopicArn}
+		alarm.Properties.AlarmActions = []interface{}{snsTopicArn}
 	}
 	return alarm
 }
@@ -92,58 +287,208 @@ func (alarm *Alarm) EvaluationPeriods(evalPeriods int) *Alarm {
 	return alarm
 }
 
+// DatapointsToAlarm configures alarm to require datapoints breaching periods out of the evaluation periods,
+// rather than all of the evaluation periods
+func (alarm *Alarm) DatapointsToAlarm(datapoints int) *Alarm {
+	alarm.Properties.DatapointsToAlarm = datapoints
+	return alarm
+}
+
+// checkDatapointsToAlarm returns an error if the alarm requires more breaching datapoints than it evaluates,
+// DatapointsToAlarm is dropped when it is the same as EvaluationPeriods since that is the CloudWatch default
+func (alarm *Alarm) checkDatapointsToAlarm() error {
+	props := &alarm.Properties
+	if props.DatapointsToAlarm == props.EvaluationPeriods {
+		props.DatapointsToAlarm = 0
+	}
+	if props.DatapointsToAlarm > props.EvaluationPeriods {
+		return errors.Errorf("alarm %s: datapoints to alarm %d exceeds evaluation periods %d",
+			props.AlarmName, props.DatapointsToAlarm, props.EvaluationPeriods)
+	}
+	return nil
+}
+
+// checkAnomalyDetection returns an error if the alarm has an anomaly detection band on a metric math expression,
+// which the band cannot be rendered around
+func (alarm *Alarm) checkAnomalyDetection() error {
+	if alarm.AnomalyBand > 0 && alarm.metricMath != nil {
+		return errors.Errorf("alarm %s: anomaly detection is not supported on the metric math expression %q",
+			alarm.Properties.AlarmName, alarm.metricMath.expression)
+	}
+	return nil
+}
+
+// AnomalyDetection configures alarm to use an anomaly detection band of anomalyBand standard deviations
+// around the metric instead of a static threshold
+func (alarm *Alarm) AnomalyDetection(anomalyBand float64) *Alarm {
+	alarm.AnomalyBand = anomalyBand
+	return alarm
+}
+
+// TreatMissingData configures how the alarm treats missing data points (e.g., TreatMissingDataBreaching),
+// call after configuring the threshold
+func (alarm *Alarm) TreatMissingData(treatment string) *Alarm {
+	if treatment == TreatMissingDataMissing {
+		treatment = "" // the CloudWatch default
+	}
+	alarm.Properties.TreatMissingData = treatment
+	return alarm
+}
+
+// ExtendedStatistic configures alarm to use a percentile statistic (e.g., p99) instead of the statistic of the threshold,
+// call after configuring the threshold
+func (alarm *Alarm) ExtendedStatistic(statistic string) *Alarm {
+	alarm.Properties.ExtendedStatistic = statistic
+	alarm.Properties.Statistic = ""
+	return alarm
+}
+
 // SumCountThreshold configures alarm for sum-based threshold with Count units
 func (alarm *Alarm) SumCountThreshold(threshold float32, period int) *Alarm {
 	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanThreshold
-	alarm.Properties.Threshold = threshold
+	alarm.Properties.Threshold = &threshold
 	alarm.Properties.Unit = cloudwatch.StandardUnitCount
 	alarm.Properties.Period = period
 	alarm.Properties.Statistic = cloudwatch.StatisticSum
-	alarm.Properties.TreatMissingData = "notBreaching"
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
 	return alarm
 }
 
 // SumNoUnitsThreshold configures alarm for sum-based threshold with Count units
 func (alarm *Alarm) SumNoUnitsThreshold(threshold float32, period int) *Alarm {
 	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanThreshold
-	alarm.Properties.Threshold = threshold
+	alarm.Properties.Threshold = &threshold
 	alarm.Properties.Unit = cloudwatch.StandardUnitNone
 	alarm.Properties.Period = period
 	alarm.Properties.Statistic = cloudwatch.StatisticSum
-	alarm.Properties.TreatMissingData = "notBreaching"
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
 	return alarm
 }
 
 // MaxSecondsThreshold configures alarm for max-based threshold with Seconds units
 func (alarm *Alarm) MaxSecondsThreshold(threshold float32, period int) *Alarm {
 	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanThreshold
-	alarm.Properties.Threshold = threshold
+	alarm.Properties.Threshold = &threshold
 	alarm.Properties.Unit = cloudwatch.StandardUnitSeconds
 	alarm.Properties.Period = period
 	alarm.Properties.Statistic = cloudwatch.StatisticMaximum
-	alarm.Properties.TreatMissingData = "notBreaching"
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
 	return alarm
 }
 
 // MaxMillisecondsThreshold configures alarm for max-based threshold with Milliseconds units
 func (alarm *Alarm) MaxMillisecondsThreshold(threshold float32, period int) *Alarm {
 	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanThreshold
-	alarm.Properties.Threshold = threshold
+	alarm.Properties.Threshold = &threshold
 	alarm.Properties.Unit = cloudwatch.StandardUnitMilliseconds
 	alarm.Properties.Period = period
 	alarm.Properties.Statistic = cloudwatch.StatisticMaximum
-	alarm.Properties.TreatMissingData = "notBreaching"
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
+	return alarm
+}
+
+// AveragePercentThreshold configures alarm for average-based threshold with Percent units
+func (alarm *Alarm) AveragePercentThreshold(threshold float32, period int) *Alarm {
+	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanThreshold
+	alarm.Properties.Threshold = &threshold
+	alarm.Properties.Unit = cloudwatch.StandardUnitPercent
+	alarm.Properties.Period = period
+	alarm.Properties.Statistic = cloudwatch.StatisticAverage
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
+	return alarm
+}
+
+// MaxPercentThreshold configures alarm for max-based threshold with Percent units
+func (alarm *Alarm) MaxPercentThreshold(threshold float32, period int) *Alarm {
+	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanThreshold
+	alarm.Properties.Threshold = &threshold
+	alarm.Properties.Unit = cloudwatch.StandardUnitPercent
+	alarm.Properties.Period = period
+	alarm.Properties.Statistic = cloudwatch.StatisticMaximum
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
 	return alarm
 }
 
 // MaxNoUnitsThreshold configures alarm for max-based threshold with MB units
 func (alarm *Alarm) MaxNoUnitsThreshold(threshold float32, period int) *Alarm {
 	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanThreshold
-	alarm.Properties.Threshold = threshold
+	alarm.Properties.Threshold = &threshold
+	alarm.Properties.Unit = cloudwatch.StandardUnitNone
+	alarm.Properties.Period = period
+	alarm.Properties.Statistic = cloudwatch.StatisticMaximum
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
+	return alarm
+}
+
+// AverageNoUnitsThreshold configures alarm for average-based threshold with no units (e.g., ratios)
+func (alarm *Alarm) AverageNoUnitsThreshold(threshold float32, period int) *Alarm {
+	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanThreshold
+	alarm.Properties.Threshold = &threshold
 	alarm.Properties.Unit = cloudwatch.StandardUnitNone
 	alarm.Properties.Period = period
+	alarm.Properties.Statistic = cloudwatch.StatisticAverage
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
+	return alarm
+}
+
+// AverageCountThreshold configures alarm for average-based threshold with Count units (e.g., the success rate of
+// metrics published as 1 for success and 0 for failure)
+func (alarm *Alarm) AverageCountThreshold(threshold float32, period int) *Alarm {
+	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanThreshold
+	alarm.Properties.Threshold = &threshold
+	alarm.Properties.Unit = cloudwatch.StandardUnitCount
+	alarm.Properties.Period = period
+	alarm.Properties.Statistic = cloudwatch.StatisticAverage
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
+	return alarm
+}
+
+// BelowThreshold configures alarm to fire when the metric falls below the threshold rather than exceeding it,
+// call after configuring the threshold
+func (alarm *Alarm) BelowThreshold() *Alarm {
+	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorLessThanThreshold
+	return alarm
+}
+
+// AtOrAboveThreshold configures alarm to fire when the metric reaches the threshold rather than exceeding it,
+// call after configuring the threshold
+func (alarm *Alarm) AtOrAboveThreshold() *Alarm {
+	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanOrEqualToThreshold
+	return alarm
+}
+
+// MaxCountThreshold configures alarm for max-based threshold with Count units
+func (alarm *Alarm) MaxCountThreshold(threshold float32, period int) *Alarm {
+	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanThreshold
+	alarm.Properties.Threshold = &threshold
+	alarm.Properties.Unit = cloudwatch.StandardUnitCount
+	alarm.Properties.Period = period
 	alarm.Properties.Statistic = cloudwatch.StatisticMaximum
-	alarm.Properties.TreatMissingData = "notBreaching"
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
+	return alarm
+}
+
+// MinBytesThreshold configures alarm for min-based threshold with Bytes units, alarming when the metric falls
+// below the threshold (e.g., free space)
+func (alarm *Alarm) MinBytesThreshold(threshold float32, period int) *Alarm {
+	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorLessThanThreshold
+	alarm.Properties.Threshold = &threshold
+	alarm.Properties.Unit = cloudwatch.StandardUnitBytes
+	alarm.Properties.Period = period
+	alarm.Properties.Statistic = cloudwatch.StatisticMinimum
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
+	return alarm
+}
+
+// MinMegabytesThreshold configures alarm for min-based threshold with Megabytes units, alarming when the metric falls
+// below the threshold (e.g., free space)
+func (alarm *Alarm) MinMegabytesThreshold(threshold float32, period int) *Alarm {
+	alarm.Properties.ComparisonOperator = cloudwatch.ComparisonOperatorLessThanThreshold
+	alarm.Properties.Threshold = &threshold
+	alarm.Properties.Unit = cloudwatch.StandardUnitMegabytes
+	alarm.Properties.Period = period
+	alarm.Properties.Statistic = cloudwatch.StatisticMinimum
+	alarm.Properties.TreatMissingData = TreatMissingDataNotBreaching
 	return alarm
 }
 
@@ -151,70 +496,341 @@ func AlarmName(alarmType, resourceName string) string {
 	return alarmPrefix + "-" + alarmType + "-" + resourceName
 }
 
+// qualifyName makes the alarm name unique by appending the logical id of the resource, the metric and the statistic,
+// e.g., PantherAlarm-LambdaErrors-test-lambda-Function-Errors-Sum
+func (alarm *Alarm) qualifyName() {
+	props := &alarm.Properties
+	statistic := props.Statistic
+	if props.ExtendedStatistic != "" {
+		statistic = props.ExtendedStatistic
+	}
+	for _, part := range []string{alarm.LogicalID, props.MetricName, statistic} {
+		if part != "" {
+			props.AlarmName += "-" + part
+		}
+	}
+}
+
+// the characters not allowed in CF logical ids, unlike cfngen.SanitizeResourceName digits are kept so resources
+// named alike but for a number (e.g., Queue1 and Queue2) have distinct ids
+var nonAlphanumeric = regexp.MustCompile(`[^[:alnum:]]`)
+
+// cfResourceName returns the CF logical id of the generated resource (e.g., an alarm or metric filter) with the name.
+// The id is derived from the name alone, which for alarms is qualified by the logical id of the resource and the metric
+// (see qualifyName), so it does not depend on the order the resources are found in and adding a resource to a template
+// does not change the ids of the other alarms, which CF would replace on update. Ids longer than CF allows are
+// truncated with a hash of the name to keep them unique.
+func cfResourceName(name string) string {
+	resourceName := nonAlphanumeric.ReplaceAllString(name, "")
+	if len(resourceName) <= maxResourceNameLength {
+		return resourceName
+	}
+	hash := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(hash[:8])
+	return resourceName[:maxResourceNameLength-len(suffix)] + suffix
+}
+
+// sortAlarms orders the alarms by name and returns an error for any names that collide, including names that
+// collide as CF resource names
+func sortAlarms(alarms []*Alarm) error {
+	sort.Slice(alarms, func(i, j int) bool {
+		return alarms[i].Properties.AlarmName < alarms[j].Properties.AlarmName
+	})
+	var collisions []string
+	resourceNames := make(map[string]string, len(alarms))
+	for _, alarm := range alarms {
+		resourceName := cfResourceName(alarm.Properties.AlarmName)
+		if collidingName, found := resourceNames[resourceName]; found {
+			collisions = append(collisions, fmt.Sprintf("%s (%s) collides with %s",
+				alarm.Properties.AlarmName, alarm.LogicalID, collidingName))
+			continue
+		}
+		resourceNames[resourceName] = alarm.Properties.AlarmName
+	}
+	if len(collisions) > 0 {
+		return errors.New("alarm names collide: " + strings.Join(collisions, ", "))
+	}
+	return nil
+}
+
 // GenerateAlarms will read the CF in yml files in the cfDir, and generate CF for CloudWatch alarms for the infrastructure.
-// NOTE: this will not work for resources referenced with Refs, this code requires constant values.
+// Names that are CF intrinsics (e.g., Ref, Fn::Sub) are passed to the alarm dimensions for CF to resolve at deploy time,
+// resources whose names cannot be resolved are skipped and reported in the Warnings of BuildAlarms.
 func GenerateAlarms(snsTopicArn string, stackOutputs map[string]string, cfDirs ...string) (alarms []*Alarm, cf []byte, err error) {
-	config := &Config{
-		snsTopicArn:  snsTopicArn,
-		stackOutputs: stackOutputs,
+	return GenerateAlarmsWithConfig(NewConfig(snsTopicArn, stackOutputs), cfDirs...)
+}
+
+// GenerateAlarmsWithConfig is GenerateAlarms with the alarm generation configured by config.
+func GenerateAlarmsWithConfig(config *Config, cfDirs ...string) (alarms []*Alarm, cf []byte, err error) {
+	template, err := BuildAlarms(config, cfDirs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	cf, err = template.Marshal(config.format)
+	if err != nil {
+		return nil, nil, err
 	}
+	return template.Alarms, cf, nil
+}
 
-	for _, cfDir := range cfDirs {
-		err := walkYamlFiles(cfDir, func(path string) (err error) {
-			fileAlarms, err := generateAlarms(path, config)
-			if err == nil {
-				alarms = append(alarms, fileAlarms...)
-			}
-			return err
-		})
+// generateSortedAlarms returns the alarms for the CF in the cfDirs ordered by name, with warnings for resources that
+// could not be alarmed
+func generateSortedAlarms(config *Config, cfDirs ...string) (alarms []*Alarm, warnings []Warning, err error) {
+	consumedQueues := make(map[string]struct{})
+	err = eachTemplate(cfDirs, func(template *cfTemplate) error {
+		templateAlarms, templateWarnings, err := generateResourceAlarms(template.path, template.resources, config)
 		if err != nil {
-			return nil, nil, err
+			return errors.Wrap(err, template.path)
 		}
+		addConsumedQueueNames(consumedQueues, template.resources)
+		alarms = append(alarms, templateAlarms...)
+		warnings = append(warnings, templateWarnings...)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	alarms = withoutConsumedQueueAlarms(alarms, consumedQueues)
+	customAlarms, err := generateCustomAlarms(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	alarms = append(alarms, customAlarms...)
+	accountAlarms, err := generateLambdaAccountAlarms(config)
+	if err != nil {
+		return nil, nil, err
 	}
+	alarms = append(alarms, accountAlarms...)
 
-	resources := make(map[string]interface{})
+	if err = sortAlarms(alarms); err != nil {
+		return nil, nil, err
+	}
+	return alarms, warnings, nil
+}
+
+// alarmResources returns the CF resources for the alarms keyed by resource name, including any supporting resources
+func alarmResources(alarms []*Alarm) (resources map[string]interface{}) {
+	resources = make(map[string]interface{})
 	for _, alarm := range alarms {
-		resources[cfngen.SanitizeResourceName(alarm.Properties.AlarmName)] = alarm
+		alarm.renderMetricMath()
+		if alarm.AnomalyBand > 0 && alarm.metricMath == nil {
+			resources[cfResourceName(alarm.Properties.AlarmName+"AnomalyDetector")] = alarm.anomalyDetection()
+		}
+		resources[cfResourceName(alarm.Properties.AlarmName)] = alarm
+		if alarm.maintenanceAlarm != nil {
+			resources[cfResourceName(alarm.maintenanceAlarm.Properties.AlarmName)] = alarm.maintenanceAlarm
+		}
 	}
+	addDependencies(resources)
+	return resources
+}
 
-	// generate CF using cfngen
-	cf, err = cfngen.NewTemplate("Panther Alarms", nil, resources, nil).CloudFormation()
+func generateAlarms(fileName string, config *Config) (alarms []*Alarm, warnings []Warning, err error) {
+	resources, err := readYamlResources(fileName)
 	if err != nil {
 		return nil, nil, err
 	}
-	return alarms, cf, nil
+	return generateResourceAlarms(fileName, resources, config)
 }
 
-func generateAlarms(fileName string, config *Config) (alarms []*Alarm, err error) {
-	yamlObj, err := readYaml(fileName)
+// generateResourceAlarms returns the alarms for the resources of the CF template in the file, with warnings for
+// resources of supported types skipped because they could not be alarmed
+func generateResourceAlarms(fileName string, resources cfResources,
+	config *Config) (alarms []*Alarm, warnings []Warning, err error) {
+
+	index := newTemplateIndex(resources)
+	resources.walk(func(logicalID, resourceType string, resource map[interface{}]interface{}) {
+		if err != nil || !config.inScope(logicalID) || config.exclusions.matches(logicalID, resource) {
+			return
+		}
+		if reason := skippedReason(logicalID, resourceType, resource, resources, config); reason != "" {
+			warnings = append(warnings, Warning{File: fileName, LogicalID: logicalID, Type: resourceType, Reason: reason})
+			return
+		}
+		for _, alarm := range alarmDispatchOnType(logicalID, resourceType, resource, resources, index, config) {
+			alarm.LogicalID = logicalID
+			alarm.templateFile = fileName
+			alarm.resourceType = resourceType
+			if err = config.configure(alarm, resourceType, resource); err != nil {
+				return
+			}
+			alarms = append(alarms, alarm)
+		}
+	})
 	if err != nil {
-		return nil, err
+		return nil, nil, errors.Wrap(err, fileName)
+	}
+
+	if config.followNestedStacks {
+		stackAlarms, stackWarnings, err := generateNestedStackAlarms(fileName, resources, config, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		alarms = append(alarms, stackAlarms...)
+		warnings = append(warnings, stackWarnings...)
 	}
 
-	walkYamlMap(yamlObj, func(resourceType string, resource map[interface{}]interface{}) {
-		alarms = append(alarms, alarmDispatchOnType(resourceType, resource, config)...)
+	return alarms, warnings, nil
+}
+
+// templateIndex is what the alarms of a resource need to know about the other resources of the template, computed
+// once per template so generating the alarms does not scan the template for each resource
+type templateIndex struct {
+	deadLetterQueues map[string]string   // see findDeadLetterQueues
+	eventSources     map[string]struct{} // see findEventSources
+}
+
+func newTemplateIndex(resources cfResources) *templateIndex {
+	return &templateIndex{
+		deadLetterQueues: findDeadLetterQueues(resources),
+		eventSources:     findEventSources(resources),
+	}
+}
+
+// configure applies the config to the alarm generated for the resource (nil for alarms not of a resource), qualifying
+// its name and then replacing its defaults
+func (config *Config) configure(alarm *Alarm, resourceType string, resource map[interface{}]interface{}) error {
+	alarm.qualifyName()
+	alarm.qualifyEnvironment(config.environment)
+	alarm.namePrefix = config.prefixName("")
+	alarm.Properties.AlarmName = alarm.namePrefix + alarm.Properties.AlarmName
+	config.profile.applyDefaults(alarm)
+	config.overrides.apply(alarm)
+	config.profile.applyOverrides(alarm)
+	config.routes.apply(alarm, resource)
+	if err := config.actions.apply(alarm); err != nil {
+		return err
+	}
+	alarm.disableActions(config)
+	if alarm.buildErr != nil {
+		return errors.Wrapf(alarm.buildErr, "alarm %s", alarm.Properties.AlarmName)
+	}
+	if err := alarm.checkPeriods(config); err != nil {
+		return err
+	}
+	if err := alarm.checkDatapointsToAlarm(); err != nil {
+		return err
+	}
+	if err := alarm.checkAnomalyDetection(); err != nil {
+		return err
+	}
+	if err := alarm.describe(config.descriptionTemplate, config.environment, resourceType, resource); err != nil {
+		return err
+	}
+	alarm.Properties.Tags = cfTags(config.tags)
+	alarm.suppressDuringMaintenance(config.maintenance, resource)
+	return nil
+}
+
+// generateNestedStackAlarms generates the alarms for the nested stacks in the resources that have local templates,
+// the alarm names are prefixed with the logical id of the stack to avoid collisions. The parents are the templates
+// enclosing the template in the file, a template nested in itself is an error.
+func generateNestedStackAlarms(fileName string, resources cfResources, config *Config,
+	parents []string) (alarms []*Alarm, warnings []Warning, err error) {
+
+	parents = append(parents, filepath.Clean(fileName))
+	stackConfig := *config
+	stackConfig.followNestedStacks = false // the nested stacks of the nested stacks are followed here
+	resources.walk(func(logicalID, resourceType string, resource map[interface{}]interface{}) {
+		if err != nil || resourceType != "AWS::CloudFormation::Stack" || config.exclusions.matches(logicalID, resource) {
+			return
+		}
+		templatePath, isLocal := nestedStackTemplatePath(fileName, resource)
+		if !isLocal {
+			warnings = append(warnings, Warning{File: fileName, LogicalID: logicalID, Type: resourceType,
+				Reason: fmt.Sprintf("the TemplateURL %v is not a local template", getResourceNestedProperty(resource, "TemplateURL"))})
+			return
+		}
+		if containsString(parents, filepath.Clean(templatePath)) {
+			err = errors.Errorf("%s: the template %s of the nested stack includes itself", logicalID, templatePath)
+			return
+		}
+		stackResources, stackErr := readYamlResources(templatePath)
+		if stackErr != nil {
+			err = errors.Wrap(stackErr, logicalID)
+			return
+		}
+		stackAlarms, stackWarnings, stackErr := generateResourceAlarms(templatePath, stackResources, &stackConfig)
+		if stackErr != nil {
+			err = errors.Wrap(stackErr, logicalID)
+			return
+		}
+		nestedAlarms, nestedWarnings, stackErr := generateNestedStackAlarms(templatePath, stackResources, config, parents)
+		if stackErr != nil {
+			err = errors.Wrap(stackErr, logicalID)
+			return
+		}
+		stackAlarms = append(stackAlarms, nestedAlarms...)
+		warnings = append(append(warnings, stackWarnings...), nestedWarnings...)
+		for _, alarm := range stackAlarms { // after the configured prefix
+			alarm.Properties.AlarmName = alarm.namePrefix + logicalID + "-" +
+				strings.TrimPrefix(alarm.Properties.AlarmName, alarm.namePrefix)
+		}
+		alarms = append(alarms, stackAlarms...)
 	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return alarms, warnings, nil
+}
 
-	return alarms, nil
+// nestedStackTemplatePath returns the path of the template of a nested stack relative to the parent template,
+// or false if the TemplateURL is remote (e.g., S3) or not a literal
+func nestedStackTemplatePath(fileName string, resource map[interface{}]interface{}) (templatePath string, isLocal bool) {
+	templateURL, ok := getResourceNestedProperty(resource, "TemplateURL").(string)
+	if !ok || templateURL == "" || strings.Contains(templateURL, "://") {
+		return "", false
+	}
+	return filepath.Join(filepath.Dir(fileName), templateURL), true
 }
 
 // dispatch on "Type" to create specific alarms
-func alarmDispatchOnType(resourceType string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+func alarmDispatchOnType(logicalID, resourceType string, resource map[interface{}]interface{},
+	resources map[string]map[interface{}]interface{}, index *templateIndex, config *Config) (alarms []*Alarm) {
+
 	switch resourceType { // this could be a map of key -> func if this gets long
+	case "AWS::CloudFront::Distribution":
+		return generateCloudFrontAlarms(logicalID, config)
+	case "AWS::Glue::Job":
+		return generateGlueJobAlarms(logicalID, resource, config)
+	case elasticsearchDomainType, openSearchDomainType:
+		return generateElasticsearchAlarms(logicalID, resource, config)
+	case "AWS::RDS::DBInstance", "AWS::RDS::DBCluster":
+		return generateRDSAlarms(logicalID, resourceType, resource, config)
+	case "AWS::KinesisFirehose::DeliveryStream":
+		return generateFirehoseAlarms(logicalID, resource, config)
+	case eventsRuleType:
+		return generateEventsRuleAlarms(logicalID, resource, config)
 	case "AWS::SNS::Topic":
-		return generateSNSAlarms(resource, config)
+		return generateSNSAlarms(logicalID, resource, config)
 	case "AWS::SQS::Queue":
-		return generateSQSAlarms(resource, config)
+		return generateSQSAlarms(logicalID, resource, index, config)
 	case "AWS::Serverless::Api":
 		return generateAPIGatewayAlarms(resource, config)
+	case restAPIType, httpAPIType:
+		return generateAPIAlarms(logicalID, resourceType, resource, config)
 	case "AWS::ElasticLoadBalancingV2::LoadBalancer":
 		return generateApplicationELBAlarms(resource, config)
+	case targetGroupType:
+		return generateTargetGroupAlarms(logicalID, resource, resources, config)
 	case "AWS::AppSync::GraphQLApi":
-		return generateAppSyncAlarms(resource, config)
+		return generateAppSyncAlarms(logicalID, resource, config)
+	case cognitoUserPoolClientType:
+		return generateCognitoAlarms(logicalID, resource, resources, config)
 	case "AWS::DynamoDB::Table":
-		return generateDynamoDBAlarms(resource, config)
-	case "AWS::Serverless::Function":
-		return generateLambdaAlarms(resource, config)
+		return generateDynamoDBAlarms(logicalID, resource, config)
+	case "AWS::Serverless::Function", "AWS::Lambda::Function": // SAM expands to the same function
+		return generateLambdaAlarms(logicalID, resource, config)
+	case lambdaAliasType:
+		return generateLambdaAliasAlarms(logicalID, resource, resources, config)
+	case "AWS::Kinesis::Stream":
+		return generateKinesisAlarms(logicalID, resource, config)
+	case "AWS::ECS::Service":
+		return generateECSServiceAlarms(logicalID, resource, resources, config)
+	case "AWS::StepFunctions::StateMachine":
+		return generateStateMachineAlarms(logicalID, resource, config)
+	case eventSourceMappingType:
+		return generateEventSourceMappingAlarms(logicalID, resource, resources, config)
 	}
 	return alarms
 }
@@ -0,0 +1,122 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+)
+
+const (
+	anomalyMetricID           = "m1"
+	anomalyBandID             = "ad1"
+	anomalyComparisonOperator = "LessThanLowerOrGreaterThanUpperThreshold"
+)
+
+// see: https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-cloudwatch-anomalydetector.html
+type AnomalyDetector struct {
+	Type       string
+	Properties AnomalyDetectorProperties
+}
+
+type AnomalyDetectorProperties struct {
+	Namespace  string
+	MetricName string
+	Dimensions []MetricDimension `json:",omitempty"`
+	Stat       string
+}
+
+// see: https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-cloudwatch-alarm-metricdataquery.html
+type MetricDataQuery struct {
+	ID         string      `json:"Id"`
+	Expression string      `json:",omitempty"`
+	Label      string      `json:",omitempty"`
+	MetricStat *MetricStat `json:",omitempty"`
+	ReturnData bool
+}
+
+type MetricStat struct {
+	Metric Metric
+	Period int
+	Stat   string
+	Unit   string `json:",omitempty"`
+}
+
+type Metric struct {
+	Namespace  string
+	MetricName string
+	Dimensions []MetricDimension `json:",omitempty"`
+}
+
+// anomalyDetection converts the alarm from a static threshold to the anomaly band of its metric,
+// returning the detector that models the metric
+func (alarm *Alarm) anomalyDetection() *AnomalyDetector {
+	if alarm.anomalyDetector != nil { // already converted
+		return alarm.anomalyDetector
+	}
+
+	props := &alarm.Properties
+	stat := props.Statistic
+	if props.ExtendedStatistic != "" {
+		stat = props.ExtendedStatistic
+	}
+	alarm.anomalyDetector = &AnomalyDetector{
+		Type: "AWS::CloudWatch::AnomalyDetector",
+		Properties: AnomalyDetectorProperties{
+			Namespace:  props.Namespace,
+			MetricName: props.MetricName,
+			Dimensions: props.Dimensions,
+			Stat:       stat,
+		},
+	}
+
+	// the metric and band are expressed as metric data queries, these are exclusive of the simple metric fields
+	props.Metrics = []MetricDataQuery{
+		{
+			ID: anomalyMetricID,
+			MetricStat: &MetricStat{
+				Metric: Metric{
+					Namespace:  props.Namespace,
+					MetricName: props.MetricName,
+					Dimensions: props.Dimensions,
+				},
+				Period: props.Period,
+				Stat:   stat,
+				Unit:   props.Unit,
+			},
+			ReturnData: true,
+		},
+		{
+			ID:         anomalyBandID,
+			Expression: fmt.Sprintf("ANOMALY_DETECTION_BAND(%s, %g)", anomalyMetricID, alarm.AnomalyBand),
+			ReturnData: true,
+		},
+	}
+	props.ComparisonOperator = anomalyComparisonOperator
+	props.ThresholdMetricID = anomalyBandID
+	props.Namespace = ""
+	props.MetricName = ""
+	props.Dimensions = nil
+	props.Period = 0
+	props.Threshold = nil
+	props.Unit = ""
+	props.Statistic = ""
+	props.ExtendedStatistic = ""
+
+	return alarm.anomalyDetector
+}
@@ -0,0 +1,101 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+const (
+	restAPIType = "AWS::ApiGateway::RestApi"
+	httpAPIType = "AWS::ApiGatewayV2::Api"
+)
+
+// apiMetricNames are the metric names by API type, HTTP APIs use their own names for the error metrics
+var apiMetricNames = map[string]struct {
+	serverErrors string
+	clientErrors string
+}{
+	restAPIType: {serverErrors: "5XXError", clientErrors: "4XXError"},
+	httpAPIType: {serverErrors: "5xx", clientErrors: "4xx"},
+}
+
+type APIAlarm struct {
+	Alarm
+}
+
+// NewAPIAlarm creates an alarm for a REST (dimension ApiName) or HTTP (dimension ApiId) API Gateway API
+func NewAPIAlarm(logicalID, alarmType, metricName, message string, resource map[interface{}]interface{},
+	config *Config) (alarm *APIAlarm) {
+
+	const metricNamespace = "AWS/ApiGateway" // same for REST and HTTP APIs
+	apiName, apiNameDimension := getResourceName("Name", logicalID, resource)
+	var dimension MetricDimension
+	if resource["Type"] == restAPIType {
+		dimension = MetricDimension{Name: "ApiName", Value: apiNameDimension}
+	} else {
+		// a Ref to an HTTP API returns the id
+		dimension = MetricDimension{Name: "ApiId", Value: cfngen.Ref{Ref: logicalID}}
+	}
+	alarmName := AlarmName(alarmType, apiName)
+	alarm = &APIAlarm{
+		Alarm: *NewAlarm(apiName, alarmName,
+			fmt.Sprintf("ApiGateway %s %s. See: %s#%s", apiName, message, documentationURL, apiName),
+			config.snsTopicArn),
+	}
+	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{dimension})
+	return alarm
+}
+
+func generateAPIAlarms(logicalID, resourceType string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+	if resourceType == httpAPIType && getResourceNestedProperty(resource, "ProtocolType") != "HTTP" {
+		return nil // websocket APIs have different metrics
+	}
+
+	alarmPrefix := "RestApi"
+	if resourceType == httpAPIType {
+		alarmPrefix = "HttpApi"
+	}
+	metricNames := apiMetricNames[resourceType]
+
+	// server errors
+	// NOTE: error metrics appear to have no units
+	alarms = append(alarms, NewAPIAlarm(logicalID, alarmPrefix+"ServerError", metricNames.serverErrors,
+		"is failing", resource, config).SumNoUnitsThreshold(0, 60*5))
+
+	// client errors are often expected (e.g., bad requests) so are optional
+	if config.apiClientErrorAlarms {
+		alarms = append(alarms, NewAPIAlarm(logicalID, alarmPrefix+"ClientError", metricNames.clientErrors,
+			"has elevated client errors", resource, config).SumNoUnitsThreshold(20, 60*5) /* tolerate a few client errors */)
+	}
+
+	// tail latency
+	alarms = append(alarms, NewAPIAlarm(logicalID, alarmPrefix+"HighLatency", "Latency",
+		"is experiencing high latency", resource, config).MaxMillisecondsThreshold(1000, 60).EvaluationPeriods(5).
+		ExtendedStatistic("p99"))
+
+	// unusual request volume
+	alarms = append(alarms, NewAPIAlarm(logicalID, alarmPrefix+"CountAnomaly", "Count",
+		"has unusual request volume", resource, config).SumCountThreshold(0, 60*5).EvaluationPeriods(3).
+		AnomalyDetection(2))
+
+	return alarms
+}
@@ -26,14 +26,14 @@ type AppSyncAlarm struct {
 	Alarm
 }
 
-func NewAppSyncAlarm(graphQlID, alarmType, metricName, message string, resource map[interface{}]interface{},
-	config *Config) (alarm *AppSyncAlarm) {
+func NewAppSyncAlarm(logicalID string, graphQlID interface{}, alarmType, metricName, message string,
+	resource map[interface{}]interface{}, config *Config) (alarm *AppSyncAlarm) {
 
 	const (
 		metricDimension = "GraphQLAPIId"
 		metricNamespace = "AWS/AppSync"
 	)
-	appSyncName := getResourceProperty("Name", resource)
+	appSyncName, _ := getResourceName("Name", logicalID, resource)
 	alarmName := AlarmName(alarmType, appSyncName)
 	alarm = &AppSyncAlarm{
 		Alarm: *NewAlarm(appSyncName, alarmName,
@@ -44,29 +44,29 @@ func NewAppSyncAlarm(graphQlID, alarmType, metricName, message string, resource
 	return alarm
 }
 
-func generateAppSyncAlarms(resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
-	// this one uses a dynamically generated metric name we get out of the stackOutputs, we only expect 1 in this application
+func generateAppSyncAlarms(logicalID string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+	// the id is generated at deploy time, when the alarms are deployed with the API the attribute resolves it
+	var graphQlID interface{} = map[string]interface{}{"Fn::GetAtt": []interface{}{logicalID, "ApiId"}}
+	// alarms deployed in another stack use the id from the stackOutputs instead, we only expect 1 in this application
 	const graphQlIDKey = "WebApplicationGraphqlApiId"
-	var graphQlID string
 	if id, found := config.stackOutputs[graphQlIDKey]; found {
 		graphQlID = id
-	} else {
-		panic(fmt.Sprintf("Missing expected %s key in %#v", graphQlIDKey, resource))
 	}
 
 	// NOTE: these metrics appear to have no units
 
 	// server errors
-	alarms = append(alarms, NewAppSyncAlarm(graphQlID, "AppSyncServerError", "5XXError",
+	alarms = append(alarms, NewAppSyncAlarm(logicalID, graphQlID, "AppSyncServerError", "5XXError",
 		"is failing", resource, config).SumNoUnitsThreshold(0, 60*5))
 
 	// client errors, here we are concerned with surfacing bugs in the Panther UI as it talks to AppSync
-	alarms = append(alarms, NewAppSyncAlarm(graphQlID, "AppSyncClientError", "4XXError",
+	alarms = append(alarms, NewAppSyncAlarm(logicalID, graphQlID, "AppSyncClientError", "4XXError",
 		"has has elevated 4XX errors", resource, config).SumNoUnitsThreshold(20, 60*5) /* tolerate a few client errors */)
 
-	// latency
-	alarms = append(alarms, NewAppSyncAlarm(graphQlID, "AppSyncHighLatency", "Latency",
-		"is experience high latency", resource, config).MaxNoUnitsThreshold(1000, 60).EvaluationPeriods(5))
+	// latency, p90 so a few slow resolvers do not alarm
+	alarms = append(alarms, NewAppSyncAlarm(logicalID, graphQlID, "AppSyncHighLatency", "Latency",
+		"is experience high latency", resource, config).MaxNoUnitsThreshold(1000, 60).EvaluationPeriods(5).
+		ExtendedStatistic("p90"))
 
 	return alarms
 }
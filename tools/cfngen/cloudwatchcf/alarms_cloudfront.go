@@ -0,0 +1,60 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+// CloudFront is a global service, its metrics are only reported in us-east-1 with the Region=Global dimension
+// so these alarms need to be deployed in us-east-1 to have data
+const cloudFrontRegionDimension = "Global"
+
+type CloudFrontAlarm struct {
+	Alarm
+}
+
+func NewCloudFrontAlarm(logicalID, alarmType, metricName, message string, config *Config) (alarm *CloudFrontAlarm) {
+	const metricNamespace = "AWS/CloudFront"
+	// distributions have no name, the Ref returns the distribution id which is only known at deploy time
+	alarmName := AlarmName(alarmType, logicalID)
+	alarm = &CloudFrontAlarm{
+		Alarm: *NewAlarm(logicalID, alarmName,
+			fmt.Sprintf("CloudFront distribution %s %s. See: %s#%s", logicalID, message, documentationURL, logicalID),
+			config.snsTopicArn),
+	}
+	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{
+		{Name: "DistributionId", Value: cfngen.Ref{Ref: logicalID}},
+		{Name: "Region", Value: cloudFrontRegionDimension},
+	})
+	return alarm
+}
+
+func generateCloudFrontAlarms(logicalID string, config *Config) (alarms []*Alarm) {
+	// errors, as a percent of all requests
+	alarms = append(alarms, NewCloudFrontAlarm(logicalID, "CloudFrontServerError", "5xxErrorRate",
+		"is failing requests", config).AveragePercentThreshold(5, 60*5))
+
+	alarms = append(alarms, NewCloudFrontAlarm(logicalID, "CloudFrontError", "TotalErrorRate",
+		"has a high error rate", config).AveragePercentThreshold(10, 60*5).EvaluationPeriods(3))
+
+	return alarms
+}
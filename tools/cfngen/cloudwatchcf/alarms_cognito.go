@@ -0,0 +1,89 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+const (
+	cognitoUserPoolClientType = "AWS::Cognito::UserPoolClient"
+
+	// alarm when fewer than this fraction of the sign-ins succeed (e.g., credential stuffing)
+	defaultCognitoSignInSuccessThreshold float32 = 0.5
+)
+
+// generateCognitoAlarms alarms on failing and throttled sign-ins of a user pool client, the thresholds can be tuned
+// with Overrides of the client (e.g., {"AppClient": {"SignInSuccesses": {"threshold": 0.8}}}). The alarms are of the
+// user pool, which is what is documented, and only the alarm names include the client.
+func generateCognitoAlarms(logicalID string, resource map[interface{}]interface{},
+	resources map[string]map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+
+	poolName, poolDimension, found := cognitoUserPool(getResourceNestedProperty(resource, "UserPoolId"), resources)
+	if !found {
+		return nil
+	}
+	clientName, _ := getResourceName("ClientName", logicalID, resource)
+	alarmResourceName := poolName + "-" + clientName
+	dimensions := []MetricDimension{
+		{Name: "UserPool", Value: poolDimension},
+		{Name: "UserPoolClient", Value: cfngen.Ref{Ref: logicalID}}, // a Ref to a client returns the id
+	}
+	alarm := func(alarmType, metricName, message string) *Alarm {
+		return NewAlarm(poolName, AlarmName(alarmType, alarmResourceName),
+			fmt.Sprintf("Cognito user pool %s client %s %s. See: %s#%s", poolName, clientName, message, documentationURL,
+				poolName),
+			config.snsTopicArn).
+			Metric("AWS/Cognito", metricName, dimensions)
+	}
+
+	// the average is the fraction of the sign-ins that succeed
+	alarms = append(alarms, alarm("CognitoSignInFailures", "SignInSuccesses",
+		fmt.Sprintf("has fewer than %d%% of sign-ins succeeding", (int)(defaultCognitoSignInSuccessThreshold*100.0))).
+		AverageCountThreshold(defaultCognitoSignInSuccessThreshold, 60*5).BelowThreshold())
+
+	// throttled calls are failed authentications for the users
+	alarms = append(alarms, alarm("CognitoSignInThrottles", "SignInThrottles", "is throttling sign-ins").
+		SumCountThreshold(0, 60*5))
+	alarms = append(alarms, alarm("CognitoTokenRefreshThrottles", "TokenRefreshThrottles",
+		"is throttling token refreshes").
+		SumCountThreshold(0, 60*5))
+
+	return alarms
+}
+
+// cognitoUserPool returns the name and the UserPool dimension of the user pool of a client, a Ref to a pool in the
+// resources (which returns the id) or a pool id, found is false otherwise
+func cognitoUserPool(userPoolID interface{},
+	resources map[string]map[interface{}]interface{}) (name string, dimension interface{}, found bool) {
+
+	if poolID := refLogicalID(userPoolID, resources); poolID != "" {
+		if resources[poolID]["Type"] != "AWS::Cognito::UserPool" {
+			return "", nil, false
+		}
+		name, _ = getResourceName("UserPoolName", poolID, resources[poolID])
+		return name, cfngen.Ref{Ref: poolID}, true
+	}
+	if poolID, isLiteral := userPoolID.(string); isLiteral && poolID != "" { // e.g., us-east-1_aBcDeFgHi
+		return poolID, poolID, true
+	}
+	return "", nil, false
+}
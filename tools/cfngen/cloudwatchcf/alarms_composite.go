@@ -0,0 +1,171 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+const compositeAlarmType = "Composite"
+
+// see: https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-cloudwatch-compositealarm.html
+type CompositeAlarm struct {
+	Resource   string `json:"-"` // use '-' tag so field is not serialized
+	Type       string
+	DependsOn  []string `json:",omitempty"`
+	Properties CompositeAlarmProperties
+}
+
+type CompositeAlarmProperties struct {
+	AlarmName                        string
+	AlarmDescription                 string        `json:",omitempty"`
+	ActionsEnabled                   *bool         `json:",omitempty"` // nil is enabled, the CloudWatch default
+	AlarmActions                     []interface{} `json:",omitempty"`
+	OKActions                        []interface{} `json:",omitempty"`
+	InsufficientDataActions          []interface{} `json:",omitempty"`
+	AlarmRule                        string
+	ActionsSuppressor                interface{} `json:",omitempty"` // alarm suppressing the actions while in ALARM
+	ActionsSuppressorWaitPeriod      int         `json:",omitempty"`
+	ActionsSuppressorExtensionPeriod int         `json:",omitempty"`
+	Tags                             []Tag       `json:",omitempty"`
+}
+
+// NewCompositeAlarm creates a composite alarm that is in ALARM when any of the alarms is in ALARM. If any of the
+// alarms is suppressed during maintenance, so is the composite alarm. The name is qualified by the logical id of the
+// resource of the alarms, if any, like the names of the alarms (see qualifyName).
+func NewCompositeAlarm(resource string, alarms []*Alarm) (compositeAlarm *CompositeAlarm) {
+	var alarmNames, dependsOn []string
+	var actionsEnabled *bool
+	var maintenance *CompositeAlarmProperties
+	actions := make(map[string]interface{}) // keyed by string form for a deterministic order
+	for _, alarm := range alarms {
+		if alarm.Properties.ActionsEnabled != nil && !*alarm.Properties.ActionsEnabled {
+			actionsEnabled = alarm.Properties.ActionsEnabled // the composite would notify for the disabled alarm
+		}
+		alarmNames = append(alarmNames, alarm.Properties.AlarmName)
+		dependsOn = append(dependsOn, cfResourceName(alarm.Properties.AlarmName))
+		alarmActions := alarm.Properties.AlarmActions
+		if alarm.maintenanceAlarm != nil { // the actions were moved to the companion
+			maintenance = &alarm.maintenanceAlarm.Properties
+			alarmActions = maintenance.AlarmActions
+		}
+		for _, action := range alarmActions {
+			actions[fmt.Sprintf("%v", action)] = action
+		}
+	}
+	sort.Strings(alarmNames)
+	sort.Strings(dependsOn)
+
+	actionKeys := make([]string, 0, len(actions))
+	for actionKey := range actions {
+		actionKeys = append(actionKeys, actionKey)
+	}
+	sort.Strings(actionKeys)
+	var alarmActions []interface{}
+	for _, actionKey := range actionKeys {
+		alarmActions = append(alarmActions, actions[actionKey])
+	}
+
+	alarmRules := make([]string, len(alarmNames))
+	for i, alarmName := range alarmNames {
+		alarmRules[i] = `ALARM("` + alarmName + `")`
+	}
+
+	compositeAlarm = &CompositeAlarm{
+		Resource:  resource,
+		Type:      "AWS::CloudWatch::CompositeAlarm",
+		DependsOn: dependsOn, // the alarms in the rule must exist before the composite alarm is created
+		Properties: CompositeAlarmProperties{
+			AlarmName:        alarms[0].namePrefix + compositeAlarmName(resource, alarms[0].LogicalID),
+			AlarmDescription: "One or more alarms for " + resource + " are firing. See: " + documentationURL + "#" + resource,
+			ActionsEnabled:   actionsEnabled,
+			AlarmActions:     alarmActions,
+			AlarmRule:        strings.Join(alarmRules, " OR "),
+			Tags:             alarms[0].Properties.Tags, // the alarms are tagged alike by the config
+		},
+	}
+	if maintenance != nil {
+		compositeAlarm.Properties.ActionsSuppressor = maintenance.ActionsSuppressor
+		compositeAlarm.Properties.ActionsSuppressorWaitPeriod = maintenance.ActionsSuppressorWaitPeriod
+		compositeAlarm.Properties.ActionsSuppressorExtensionPeriod = maintenance.ActionsSuppressorExtensionPeriod
+	}
+	return compositeAlarm
+}
+
+func compositeAlarmName(resource, logicalID string) string {
+	if logicalID == "" {
+		return AlarmName(compositeAlarmType, resource)
+	}
+	return AlarmName(compositeAlarmType, resource) + "-" + logicalID
+}
+
+// compositeAlarmKey returns what the alarm is grouped by, the logical id of the resource in its template or the name
+// for alarms not of a resource in the CF (e.g., custom alarms)
+func compositeAlarmKey(alarm *Alarm) string {
+	if alarm.LogicalID != "" {
+		return "resource:" + alarm.templateFile + ":" + alarm.LogicalID
+	}
+	return "name:" + alarm.Resource
+}
+
+// GenerateCompositeAlarms will group the alarms by the logical resource they monitor and generate CF for CloudWatch composite
+// alarms so there is a single notification per resource. The grouped alarms have their actions removed so only the
+// composite alarm notifies, and are included in the generated CF so the template is complete.
+// NOTE: resources with a single alarm are left as is, there is nothing to group.
+func GenerateCompositeAlarms(alarms []*Alarm) (compositeAlarms []*CompositeAlarm, cf []byte, err error) {
+	resourceAlarms := make(map[string][]*Alarm) // by compositeAlarmKey
+	var resourceOrder []string
+	for _, alarm := range alarms {
+		key := compositeAlarmKey(alarm)
+		if _, found := resourceAlarms[key]; !found {
+			resourceOrder = append(resourceOrder, key)
+		}
+		resourceAlarms[key] = append(resourceAlarms[key], alarm)
+	}
+
+	for _, key := range resourceOrder {
+		if len(resourceAlarms[key]) < 2 {
+			continue
+		}
+		compositeAlarm := NewCompositeAlarm(resourceAlarms[key][0].Resource, resourceAlarms[key])
+		for _, alarm := range resourceAlarms[key] {
+			alarm.Properties.AlarmActions = nil // only the composite alarm notifies
+			alarm.Properties.OKActions = nil
+			alarm.Properties.InsufficientDataActions = nil
+			alarm.maintenanceAlarm = nil // the composite alarm is suppressed instead
+		}
+		compositeAlarms = append(compositeAlarms, compositeAlarm)
+	}
+
+	resources := alarmResources(alarms)
+	for _, compositeAlarm := range compositeAlarms {
+		resources[cfResourceName(compositeAlarm.Properties.AlarmName)] = compositeAlarm
+	}
+
+	// generate CF using cfngen
+	cf, err = cfngen.NewTemplate("Panther Composite Alarms", nil, resources, nil).CloudFormation()
+	if err != nil {
+		return nil, nil, err
+	}
+	return compositeAlarms, cf, nil
+}
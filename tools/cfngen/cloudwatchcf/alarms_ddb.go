@@ -20,48 +20,133 @@ package cloudwatchcf
 
 import (
 	"fmt"
+	"strconv"
 )
 
 type DynamoDBAlarm struct {
 	Alarm
 }
 
-func NewDynamoDBAlarm(operation, alarmType, metricName, message string, resource map[interface{}]interface{},
-	config *Config) (alarm *DynamoDBAlarm) {
+func NewDynamoDBAlarm(logicalID, operation, alarmType, metricName, message string,
+	resource map[interface{}]interface{}, config *Config) (alarm *DynamoDBAlarm) {
 
 	const (
 		metricDimension = "TableName"
 		metricNamespace = "AWS/DynamoDB"
 	)
-	tableName := getResourceProperty(metricDimension, resource)
+	tableName, tableDimension := getResourceName(metricDimension, logicalID, resource)
 	alarmName := AlarmName(alarmType, tableName)
 	alarm = &DynamoDBAlarm{
 		Alarm: *NewAlarm(tableName, alarmName,
 			fmt.Sprintf("DynamoDB %s %s %s operations. See: %s#%s", tableName, message, operation, documentationURL, tableName),
 			config.snsTopicArn),
 	}
-	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: tableName},
+	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: tableDimension},
 		{Name: "Operation", Value: operation}})
 	return alarm
 }
 
-func generateDynamoDBAlarms(resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+// NewDynamoDBTableAlarm creates an alarm on a table metric, if indexName is set the alarm is on the global secondary index
+func NewDynamoDBTableAlarm(logicalID, indexName, alarmType, metricName, message string,
+	resource map[interface{}]interface{}, config *Config) (alarm *DynamoDBAlarm) {
+
+	const (
+		metricDimension = "TableName"
+		metricNamespace = "AWS/DynamoDB"
+	)
+	tableName, tableDimension := getResourceName(metricDimension, logicalID, resource)
+	dimensions := []MetricDimension{{Name: metricDimension, Value: tableDimension}}
+	resourceName := tableName
+	if indexName != "" {
+		dimensions = append(dimensions, MetricDimension{Name: "GlobalSecondaryIndexName", Value: indexName})
+		resourceName = tableName + "-" + indexName
+	}
+	alarmName := AlarmName(alarmType, resourceName)
+	alarm = &DynamoDBAlarm{
+		Alarm: *NewAlarm(tableName, alarmName,
+			fmt.Sprintf("DynamoDB %s %s. See: %s#%s", resourceName, message, documentationURL, tableName),
+			config.snsTopicArn),
+	}
+	alarm.Alarm.Metric(metricNamespace, metricName, dimensions)
+	return alarm
+}
+
+func generateDynamoDBAlarms(logicalID string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
 	// NOTE: error metrics appear to have no units
 	operations := []string{"GetItem", "PutItem", "UpdateItem", "Scan", "BatchWriteItem"}
 
 	for _, operation := range operations {
 		// errors
-		alarms = append(alarms, NewDynamoDBAlarm(operation, "DDB"+operation+"Error", "SystemErrors",
+		alarms = append(alarms, NewDynamoDBAlarm(logicalID, operation, "DDB"+operation+"Error", "SystemErrors",
 			"is failing", resource, config).SumNoUnitsThreshold(0, 60*5))
 
 		// throttles
-		alarms = append(alarms, NewDynamoDBAlarm(operation, "DDB"+operation+"Throttle", "ThrottledRequests",
+		alarms = append(alarms, NewDynamoDBAlarm(logicalID, operation, "DDB"+operation+"Throttle", "ThrottledRequests",
 			"is throttling", resource, config).SumNoUnitsThreshold(0, 60*5))
 
 		// latency
-		alarms = append(alarms, NewDynamoDBAlarm(operation, "DDB"+operation+"HighLatency", "SuccessfulRequestLatency",
+		alarms = append(alarms, NewDynamoDBAlarm(logicalID, operation, "DDB"+operation+"HighLatency", "SuccessfulRequestLatency",
 			"is experiencing high latency", resource, config).MaxMillisecondsThreshold(1000, 60).EvaluationPeriods(5))
 	}
 
+	// NOTE: UserErrors is only reported for the account (no TableName dimension) so cannot be alarmed on per table
+
+	// throttles for the table and each global secondary index (which are provisioned separately)
+	indexNames := append([]string{""}, getDynamoDBIndexNames(resource)...)
+	for _, indexName := range indexNames {
+		alarms = append(alarms, NewDynamoDBTableAlarm(logicalID, indexName, "DDBReadThrottle", "ReadThrottleEvents",
+			"is throttling reads", resource, config).SumCountThreshold(0, 60*5))
+		alarms = append(alarms, NewDynamoDBTableAlarm(logicalID, indexName, "DDBWriteThrottle", "WriteThrottleEvents",
+			"is throttling writes", resource, config).SumCountThreshold(0, 60*5))
+	}
+
+	// capacity is only limited for provisioned tables, which is the default billing mode
+	billingMode, _ := getResourceNestedProperty(resource, "BillingMode").(string)
+	if billingMode == "" || billingMode == "PROVISIONED" {
+		// alarm when sustained usage is approaching the provisioned capacity
+		const highCapacityThreshold float32 = 0.8
+		const capacityPeriod = 60 * 5
+		// a capacity only known at deploy time (e.g., a Ref to a parameter) has no threshold to alarm with
+		if readCapacity, found := getDynamoDBCapacity(resource, "ReadCapacityUnits"); found {
+			alarms = append(alarms, NewDynamoDBTableAlarm(logicalID, "", "DDBHighReadCapacity", "ConsumedReadCapacityUnits",
+				fmt.Sprintf("is using more than %d%% of provisioned read capacity (%d)", (int)(highCapacityThreshold*100.0),
+					(int)(readCapacity)), resource, config).
+				SumCountThreshold(readCapacity*highCapacityThreshold*capacityPeriod, capacityPeriod).EvaluationPeriods(3))
+		}
+		if writeCapacity, found := getDynamoDBCapacity(resource, "WriteCapacityUnits"); found {
+			alarms = append(alarms, NewDynamoDBTableAlarm(logicalID, "", "DDBHighWriteCapacity", "ConsumedWriteCapacityUnits",
+				fmt.Sprintf("is using more than %d%% of provisioned write capacity (%d)", (int)(highCapacityThreshold*100.0),
+					(int)(writeCapacity)), resource, config).
+				SumCountThreshold(writeCapacity*highCapacityThreshold*capacityPeriod, capacityPeriod).EvaluationPeriods(3))
+		}
+	}
+
 	return alarms
 }
+
+// getDynamoDBIndexNames returns the names of the global secondary indexes of the table
+func getDynamoDBIndexNames(resource map[interface{}]interface{}) (indexNames []string) {
+	indexes, _ := getResourceNestedProperty(resource, "GlobalSecondaryIndexes").([]interface{})
+	for _, index := range indexes {
+		if indexMap, ok := index.(map[interface{}]interface{}); ok {
+			if indexName, ok := indexMap["IndexName"].(string); ok {
+				indexNames = append(indexNames, indexName)
+			}
+		}
+	}
+	return indexNames
+}
+
+// getDynamoDBCapacity returns the provisioned capacity units (per second) of the table, found is false if the capacity
+// is not a literal
+func getDynamoDBCapacity(resource map[interface{}]interface{}, key string) (capacityUnits float32, found bool) {
+	switch capacity := getResourceNestedProperty(resource, "ProvisionedThroughput", key).(type) {
+	case int:
+		return (float32)(capacity), true
+	case string:
+		if floatVal, err := strconv.ParseFloat(capacity, 32); err == nil {
+			return (float32)(floatVal), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,179 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+const defaultECSUtilizationThreshold float32 = 85 // percent of reserved CPU or memory
+
+type ECSServiceAlarm struct {
+	Alarm
+}
+
+func NewECSServiceAlarm(serviceName, alarmType, namespace, metricName, message string, dimensions []MetricDimension,
+	config *Config) (alarm *ECSServiceAlarm) {
+
+	alarmName := AlarmName(alarmType, serviceName)
+	alarm = &ECSServiceAlarm{
+		Alarm: *NewAlarm(serviceName, alarmName,
+			fmt.Sprintf("ECS service %s %s. See: %s#%s", serviceName, message, documentationURL, serviceName),
+			config.snsTopicArn),
+	}
+	alarm.Alarm.Metric(namespace, metricName, dimensions)
+	return alarm
+}
+
+func generateECSServiceAlarms(logicalID string, resource map[interface{}]interface{},
+	resources map[string]map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+
+	const metricNamespace = "AWS/ECS"
+	serviceName, serviceDimension := getResourceName("ServiceName", logicalID, resource)
+	if _, isRef := serviceDimension.(cfngen.Ref); isRef {
+		// the name is generated by CF, a Ref to a service returns the arn so use the Name attribute
+		serviceDimension = map[string]interface{}{"Fn::GetAtt": []interface{}{logicalID, "Name"}}
+	}
+	dimensions := []MetricDimension{
+		{Name: "ClusterName", Value: ecsClusterDimension(resource, resources)},
+		{Name: "ServiceName", Value: serviceDimension},
+	}
+
+	// utilization of the reserved capacity, sustained for 15 min
+	alarms = append(alarms, NewECSServiceAlarm(serviceName, "ECSServiceHighCPU", metricNamespace, "CPUUtilization",
+		"is using too much CPU", dimensions, config).
+		AveragePercentThreshold(defaultECSUtilizationThreshold, 60*5).EvaluationPeriods(3))
+	alarms = append(alarms, NewECSServiceAlarm(serviceName, "ECSServiceHighMemory", metricNamespace, "MemoryUtilization",
+		"is using too much memory", dimensions, config).
+		AveragePercentThreshold(defaultECSUtilizationThreshold, 60*5).EvaluationPeriods(3))
+
+	// unhealthy tasks behind a load balancer, target groups without one are reported by their warning (see skippedReason)
+	for _, targetGroupID := range ecsServiceTargetGroups(resource, resources) {
+		loadBalancerDimension := targetGroupLoadBalancerDimension(targetGroupID, resources, config)
+		if loadBalancerDimension == nil {
+			continue
+		}
+		alarms = append(alarms, NewECSServiceAlarm(serviceName, "ECSServiceUnhealthy"+targetGroupID, "AWS/ApplicationELB",
+			"UnHealthyHostCount", "has unhealthy tasks", targetGroupDimensions(targetGroupID, loadBalancerDimension), config).
+			MaxNoUnitsThreshold(0, 60*5))
+	}
+
+	return alarms
+}
+
+// ecsClusterDimension returns the cluster name of the service, a Ref to a cluster returns the name
+func ecsClusterDimension(resource map[interface{}]interface{}, resources map[string]map[interface{}]interface{}) interface{} {
+	cluster := getResourceNestedProperty(resource, "Cluster")
+	if logicalID := refLogicalID(cluster, resources); logicalID != "" {
+		return cfngen.Ref{Ref: logicalID}
+	}
+	if logicalID := getAttLogicalID(cluster); logicalID != "" {
+		return cfngen.Ref{Ref: logicalID}
+	}
+	switch clusterVal := cluster.(type) {
+	case string:
+		// either the name or the arn, e.g., arn:aws:ecs:us-east-1:123456789012:cluster/test-cluster
+		return clusterVal[strings.LastIndex(clusterVal, "/")+1:]
+	case nil:
+		return "default" // services without a cluster run in the default cluster
+	}
+	return cfIntrinsic(cluster)
+}
+
+// ecsServiceTargetGroups returns the logical ids of the target groups in the template the service is registered with
+func ecsServiceTargetGroups(resource map[interface{}]interface{},
+	resources map[string]map[interface{}]interface{}) (targetGroupIDs []string) {
+
+	loadBalancers, _ := getResourceNestedProperty(resource, "LoadBalancers").([]interface{})
+	for _, loadBalancer := range loadBalancers {
+		loadBalancerMap, _ := loadBalancer.(map[interface{}]interface{})
+		if logicalID := refLogicalID(loadBalancerMap["TargetGroupArn"], resources); logicalID != "" {
+			targetGroupIDs = append(targetGroupIDs, logicalID)
+		}
+	}
+	return targetGroupIDs
+}
+
+// targetGroupLoadBalancer returns the logical id of the load balancer with a listener forwarding to the target group,
+// by default or with a listener rule, or "" if there is none in the template
+func targetGroupLoadBalancer(targetGroupID string, resources map[string]map[interface{}]interface{}) string {
+	forwards := func(actions interface{}) bool {
+		actionList, _ := actions.([]interface{})
+		for _, action := range actionList {
+			actionMap, _ := action.(map[interface{}]interface{})
+			if refLogicalID(actionMap["TargetGroupArn"], resources) == targetGroupID {
+				return true
+			}
+		}
+		return false
+	}
+	listenerLoadBalancer := func(listenerID string) string {
+		return refLogicalID(getResourceNestedProperty(resources[listenerID], "LoadBalancerArn"), resources)
+	}
+
+	// sorted so the same load balancer is found if several forward to the target group
+	logicalIDs := make([]string, 0, len(resources))
+	for logicalID := range resources {
+		logicalIDs = append(logicalIDs, logicalID)
+	}
+	sort.Strings(logicalIDs)
+	for _, logicalID := range logicalIDs {
+		resource := resources[logicalID]
+		switch resource["Type"] {
+		case "AWS::ElasticLoadBalancingV2::Listener":
+			if forwards(getResourceNestedProperty(resource, "DefaultActions")) {
+				return listenerLoadBalancer(logicalID)
+			}
+		case "AWS::ElasticLoadBalancingV2::ListenerRule":
+			listenerID := refLogicalID(getResourceNestedProperty(resource, "ListenerArn"), resources)
+			if forwards(getResourceNestedProperty(resource, "Actions")) && listenerID != "" {
+				return listenerLoadBalancer(listenerID)
+			}
+		}
+	}
+	return ""
+}
+
+// targetGroupLoadBalancerDimension returns the LoadBalancer dimension of the metrics of the target group, the load
+// balancer forwarding to it in the template or else the one in the stackOutputs (see generateApplicationELBAlarms),
+// nil if there is neither
+func targetGroupLoadBalancerDimension(targetGroupID string, resources map[string]map[interface{}]interface{},
+	config *Config) interface{} {
+
+	if loadBalancerID := targetGroupLoadBalancer(targetGroupID, resources); loadBalancerID != "" {
+		return map[string]interface{}{"Fn::GetAtt": []interface{}{loadBalancerID, "LoadBalancerFullName"}}
+	}
+	if loadBalancer, found := config.stackOutputs[loadBalancerOutputKey]; found {
+		return loadBalancer
+	}
+	return nil
+}
+
+// targetGroupDimensions returns the dimensions of the AWS/ApplicationELB metrics of the target group, which are the
+// suffixes of the arns (e.g., targetgroup/my-targets/73e2d6bc24d8a067 and app/my-lb/50dc6c495c0c9188)
+func targetGroupDimensions(targetGroupID string, loadBalancerDimension interface{}) []MetricDimension {
+	return []MetricDimension{
+		{Name: "TargetGroup", Value: map[string]interface{}{"Fn::GetAtt": []interface{}{targetGroupID, "TargetGroupFullName"}}},
+		{Name: "LoadBalancer", Value: loadBalancerDimension},
+	}
+}
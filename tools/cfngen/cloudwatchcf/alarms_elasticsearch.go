@@ -0,0 +1,90 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+const (
+	elasticsearchDomainType = "AWS::Elasticsearch::Domain"
+	openSearchDomainType    = "AWS::OpenSearchService::Domain" // the renamed service, with the same metrics
+
+	// see: https://docs.aws.amazon.com/elasticsearch-service/latest/developerguide/cloudwatch-alarms.html
+	defaultElasticsearchFreeStorageThreshold float32 = 20 * 1024 // MB per node, when the volume size is not known
+	elasticsearchFreeStorageFraction         float32 = 0.25      // of the volume of each node
+	defaultElasticsearchJVMThreshold         float32 = 80        // percent
+	defaultElasticsearchCPUThreshold         float32 = 80        // percent
+)
+
+type ElasticsearchAlarm struct {
+	Alarm
+}
+
+func NewElasticsearchAlarm(logicalID, alarmType, metricName, message string, resource map[interface{}]interface{},
+	config *Config) (alarm *ElasticsearchAlarm) {
+
+	const (
+		metricDimension = "DomainName"
+		metricNamespace = "AWS/ES" // for OpenSearch too
+	)
+	domainName, domainDimension := getResourceName(metricDimension, logicalID, resource) // a Ref returns the name
+	alarmName := AlarmName(alarmType, domainName)
+	alarm = &ElasticsearchAlarm{
+		Alarm: *NewAlarm(domainName, alarmName,
+			fmt.Sprintf("Elasticsearch domain %s %s. See: %s#%s", domainName, message, documentationURL, domainName),
+			config.snsTopicArn),
+	}
+	// the metrics of domains are qualified by the account
+	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{
+		{Name: metricDimension, Value: domainDimension},
+		{Name: "ClientId", Value: cfngen.Ref{Ref: "AWS::AccountId"}},
+	})
+	return alarm
+}
+
+func generateElasticsearchAlarms(logicalID string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+	// a primary shard is not allocated, some data is not available
+	alarms = append(alarms, NewElasticsearchAlarm(logicalID, "ElasticsearchClusterRed", "ClusterStatus.red",
+		"has unallocated primary shards", resource, config).MaxNoUnitsThreshold(1, 60).AtOrAboveThreshold())
+
+	// the node with the least free space, writes are blocked when it is full
+	freeStorageThreshold := defaultElasticsearchFreeStorageThreshold
+	if volumeSize, err := strconv.ParseFloat(
+		fmt.Sprintf("%v", getResourceNestedProperty(resource, "EBSOptions", "VolumeSize")), 32); err == nil {
+
+		freeStorageThreshold = float32(volumeSize) * 1024 * elasticsearchFreeStorageFraction // GiB to MB
+	}
+	alarms = append(alarms, NewElasticsearchAlarm(logicalID, "ElasticsearchLowStorage", "FreeStorageSpace",
+		"is running out of storage", resource, config).MinMegabytesThreshold(freeStorageThreshold, 60))
+
+	// sustained pressure leads to out of memory errors
+	alarms = append(alarms, NewElasticsearchAlarm(logicalID, "ElasticsearchHighJVMMemoryPressure", "JVMMemoryPressure",
+		"is running out of JVM memory", resource, config).
+		MaxPercentThreshold(defaultElasticsearchJVMThreshold, 60*5).For(15*time.Minute))
+
+	alarms = append(alarms, NewElasticsearchAlarm(logicalID, "ElasticsearchHighCPU", "CPUUtilization",
+		"is using too much CPU", resource, config).
+		MaxPercentThreshold(defaultElasticsearchCPUThreshold, 60*15).For(45*time.Minute))
+
+	return alarms
+}
@@ -20,6 +20,14 @@ package cloudwatchcf
 
 import (
 	"fmt"
+	"time"
+)
+
+const (
+	targetGroupType = "AWS::ElasticLoadBalancingV2::TargetGroup"
+
+	// the stackOutputs key of the full name of the load balancer, we only expect 1 in this application
+	loadBalancerOutputKey = "WebApplicationLoadBalancerFullName"
 )
 
 type ApplicationELB struct {
@@ -45,13 +53,12 @@ func NewApplicationELBAlarm(loadBalancer, alarmType, metricName, message string,
 }
 
 func generateApplicationELBAlarms(resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
-	// this one uses a dynamically generated metric name we get out of the stackOutputs, we only expect 1 in this application
-	const loadBalancerKey = "WebApplicationLoadBalancerFullName"
+	// this one uses a dynamically generated metric name we get out of the stackOutputs
 	var loadBalancer string
-	if lb, found := config.stackOutputs[loadBalancerKey]; found {
+	if lb, found := config.stackOutputs[loadBalancerOutputKey]; found {
 		loadBalancer = lb
 	} else {
-		panic(fmt.Sprintf("Missing expected %s key in %#v", loadBalancerKey, resource))
+		panic(fmt.Sprintf("Missing expected %s key in %#v", loadBalancerOutputKey, resource))
 	}
 
 	// NOTE: these metrics appear to have no units
@@ -75,3 +82,52 @@ func generateApplicationELBAlarms(resource map[interface{}]interface{}, config *
 
 	return alarms
 }
+
+// generateTargetGroupAlarms alarms on the health, errors and latency of the targets of an ALB target group. The metrics
+// are of the target group behind a load balancer, in the template or the stackOutputs, target groups without one are
+// skipped (see skippedReason).
+func generateTargetGroupAlarms(logicalID string, resource map[interface{}]interface{},
+	resources map[string]map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+
+	loadBalancerDimension := targetGroupLoadBalancerDimension(logicalID, resources, config)
+	if loadBalancerDimension == nil {
+		return nil
+	}
+	targetGroupName, _ := getResourceName("Name", logicalID, resource)
+	dimensions := targetGroupDimensions(logicalID, loadBalancerDimension)
+	alarm := func(alarmType, metricName, message string) *Alarm {
+		return NewAlarm(targetGroupName, AlarmName(alarmType, targetGroupName),
+			fmt.Sprintf("ALB target group %s %s. See: %s#%s", targetGroupName, message, documentationURL, targetGroupName),
+			config.snsTopicArn).
+			Metric("AWS/ApplicationELB", metricName, dimensions)
+	}
+
+	// the ECS services registered with the target group alarm on their unhealthy tasks
+	if !isECSServiceTargetGroup(logicalID, resources) {
+		alarms = append(alarms, alarm("ALBTargetGroupUnhealthy", "UnHealthyHostCount", "has unhealthy hosts").
+			MaxNoUnitsThreshold(1, 60).AtOrAboveThreshold().For(5*time.Minute))
+	}
+
+	// NOTE: these metrics appear to have no units
+	alarms = append(alarms, alarm("ALBTargetGroupError", "HTTPCode_Target_5XX_Count", "has elevated Target 5XX errors").
+		SumNoUnitsThreshold(5, 60*5) /* tolerate a few errors */)
+
+	// tail latency, sustained for 5 min
+	alarms = append(alarms, alarm("ALBTargetGroupHighLatency", "TargetResponseTime", "is experiencing high latency").
+		MaxSecondsThreshold(1, 60).ExtendedStatistic("p99").For(5*time.Minute))
+
+	return alarms
+}
+
+// isECSServiceTargetGroup returns true if an ECS service in the template is registered with the target group
+func isECSServiceTargetGroup(targetGroupID string, resources map[string]map[interface{}]interface{}) bool {
+	for _, resource := range resources {
+		if resource["Type"] != "AWS::ECS::Service" {
+			continue
+		}
+		if containsString(ecsServiceTargetGroups(resource, resources), targetGroupID) {
+			return true
+		}
+	}
+	return false
+}
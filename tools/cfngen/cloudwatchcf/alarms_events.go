@@ -0,0 +1,78 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+const eventsRuleType = "AWS::Events::Rule"
+
+type EventsRuleAlarm struct {
+	Alarm
+}
+
+func NewEventsRuleAlarm(logicalID, alarmType, metricName, message string, resource map[interface{}]interface{},
+	config *Config) (alarm *EventsRuleAlarm) {
+
+	const (
+		metricDimension = "RuleName"
+		metricNamespace = "AWS/Events"
+	)
+	ruleName, ruleDimension := getResourceName("Name", logicalID, resource)
+	dimensions := []MetricDimension{{Name: metricDimension, Value: ruleDimension}}
+	// rules on the default bus have only the rule name, rules on custom buses are qualified by the bus
+	if busName := getResourceNestedProperty(resource, "EventBusName"); busName != nil && busName != "default" {
+		if _, isRef := ruleDimension.(cfngen.Ref); isRef {
+			// a Ref to a rule on a custom bus returns bus|rule
+			ruleDimension = map[string]interface{}{"Fn::Select": []interface{}{1,
+				map[string]interface{}{"Fn::Split": []interface{}{"|", ruleDimension}}}}
+		}
+		dimensions = []MetricDimension{
+			{Name: "EventBusName", Value: cfIntrinsic(busName)},
+			{Name: metricDimension, Value: ruleDimension},
+		}
+	}
+	alarmName := AlarmName(alarmType, ruleName)
+	alarm = &EventsRuleAlarm{
+		Alarm: *NewAlarm(ruleName, alarmName,
+			fmt.Sprintf("EventBridge rule %s %s. See: %s#%s", ruleName, message, documentationURL, ruleName),
+			config.snsTopicArn),
+	}
+	alarm.Alarm.Metric(metricNamespace, metricName, dimensions)
+	return alarm
+}
+
+func generateEventsRuleAlarms(logicalID string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+	// rules without targets (e.g., a schedule that is yet to be wired up) have no invocations to fail
+	if targets, _ := getResourceNestedProperty(resource, "Targets").([]interface{}); len(targets) == 0 {
+		return nil
+	}
+
+	// the targets are failing, the events are dropped once retries are exhausted
+	alarms = append(alarms, NewEventsRuleAlarm(logicalID, "EventsRuleFailedInvocations", "FailedInvocations",
+		"is failing to invoke its targets", resource, config).SumCountThreshold(0, 60*5))
+
+	// invocations are delayed
+	alarms = append(alarms, NewEventsRuleAlarm(logicalID, "EventsRuleThrottled", "ThrottledRules",
+		"is being throttled", resource, config).SumCountThreshold(0, 60*5))
+
+	return alarms
+}
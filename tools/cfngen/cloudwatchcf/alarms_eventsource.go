@@ -0,0 +1,163 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"strings"
+)
+
+const eventSourceMappingType = "AWS::Lambda::EventSourceMapping"
+
+// generateEventSourceMappingAlarms alarms on the lag of the Lambda consuming an SQS queue or Kinesis stream, which can
+// fall behind without errors. The alarms name the function so it is clear which consumer is behind.
+func generateEventSourceMappingAlarms(logicalID string, resource map[interface{}]interface{},
+	resources map[string]map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+
+	functionName, functionDimension := eventSourceFunction(logicalID, getResourceNestedProperty(resource, "FunctionName"),
+		resources)
+	sourceArn := getResourceNestedProperty(resource, "EventSourceArn")
+	sourceType, sourceName, sourceDimension := eventSource(sourceArn, resources)
+	switch sourceType {
+	case "AWS::SQS::Queue":
+		// as for the queues, nothing should be older than 5min
+		const tooOldSec float32 = 60.0 * 5.0
+		alarms = append(alarms, NewSQSAlarm(sourceName, sourceDimension, "SQSConsumerLag", "ApproximateAgeOfOldestMessage",
+			"has items not being processed at the expected rate by Lambda "+functionName, resource, config).
+			MaxSecondsThreshold(tooOldSec, 60*5))
+	case "AWS::Kinesis::Stream":
+		// the age of the last record read by the function, the stream alarm covers all of its consumers together
+		alarm := NewAlarm(functionName, AlarmName("LambdaIteratorAge", functionName),
+			fmt.Sprintf("Lambda %s is not reading Kinesis stream %s at the expected rate. See: %s#%s",
+				functionName, sourceName, documentationURL, functionName),
+			config.snsTopicArn)
+		alarm.Metric("AWS/Lambda", "IteratorAge", []MetricDimension{{Name: "FunctionName", Value: functionDimension}})
+		alarms = append(alarms, alarm.MaxMillisecondsThreshold(config.kinesisIteratorAgeThreshold, 60*5))
+	}
+	return alarms // other sources are skipped (see skippedReason)
+}
+
+// eventSourceFunction returns the name and dimension of the function of an event source mapping, which may be a
+// Ref or GetAtt of a function in the resources, a name or an ARN
+func eventSourceFunction(logicalID string, functionName interface{},
+	resources map[string]map[interface{}]interface{}) (name string, dimension interface{}) {
+
+	functionID := refLogicalID(functionName, resources)
+	if functionID == "" {
+		functionID = getAttLogicalID(functionName)
+	}
+	if function, found := resources[functionID]; found {
+		return getResourceName("FunctionName", functionID, function)
+	}
+
+	switch val := functionName.(type) {
+	case string:
+		// e.g., arn:aws:lambda:us-east-1:123456789012:function:my-function:alias
+		if index := strings.Index(val, ":function:"); index >= 0 {
+			val = strings.SplitN(val[index+len(":function:"):], ":", 2)[0]
+		}
+		return val, val
+	case map[interface{}]interface{}:
+		return logicalID, cfIntrinsic(val)
+	}
+	return logicalID, functionName
+}
+
+// eventSource returns the resource type, name and metric dimension of the source of an event source mapping, a GetAtt
+// of a queue or stream in the resources or a literal ARN. The type is "" if the source is not known.
+func eventSource(sourceArn interface{},
+	resources map[string]map[interface{}]interface{}) (resourceType, name string, dimension interface{}) {
+
+	sourceID := getAttLogicalID(sourceArn)
+	if source, found := resources[sourceID]; found {
+		switch resourceType, _ = source["Type"].(string); resourceType {
+		case "AWS::SQS::Queue":
+			name, dimension = sqsQueueName(sourceID, source)
+		case "AWS::Kinesis::Stream":
+			name, dimension = getResourceName("Name", sourceID, source)
+		default:
+			return "", "", nil
+		}
+		return resourceType, name, dimension
+	}
+
+	// e.g., arn:aws:sqs:us-east-1:123456789012:my-queue or arn:aws:kinesis:us-east-1:123456789012:stream/my-stream
+	arn, isLiteral := sourceArn.(string)
+	parts := strings.SplitN(arn, ":", 6)
+	if !isLiteral || len(parts) < 6 {
+		return "", "", nil
+	}
+	switch parts[2] {
+	case "sqs":
+		return "AWS::SQS::Queue", parts[5], parts[5]
+	case "kinesis":
+		name = strings.TrimPrefix(parts[5], "stream/")
+		return "AWS::Kinesis::Stream", name, name
+	}
+	return "", "", nil
+}
+
+// findEventSources returns the logical ids of the resources consumed by an event source mapping in the resources,
+// the mapping alarms on their lag naming the consumer
+func findEventSources(resources map[string]map[interface{}]interface{}) (eventSources map[string]struct{}) {
+	eventSources = make(map[string]struct{})
+	for _, resource := range resources {
+		if resource["Type"] != eventSourceMappingType {
+			continue
+		}
+		if sourceID := getAttLogicalID(getResourceNestedProperty(resource, "EventSourceArn")); sourceID != "" {
+			eventSources[sourceID] = struct{}{}
+		}
+	}
+	return eventSources
+}
+
+// addConsumedQueueNames adds the names of the queues consumed by event source mappings in the resources of a template
+// to the queueNames, for mappings with a literal source ARN or a source in the template with a literal name
+func addConsumedQueueNames(queueNames map[string]struct{}, resources cfResources) {
+	for _, resource := range resources {
+		if resource["Type"] != eventSourceMappingType {
+			continue
+		}
+		sourceType, _, sourceDimension := eventSource(getResourceNestedProperty(resource, "EventSourceArn"), resources)
+		if queueName, isLiteral := sourceDimension.(string); isLiteral && sourceType == "AWS::SQS::Queue" {
+			queueNames[queueName] = struct{}{}
+		}
+	}
+}
+
+// withoutConsumedQueueAlarms removes the age alarms of the queues consumed by mappings in any of the templates (see
+// addConsumedQueueNames), the mappings alarm on the same metric naming the consumer. Mappings in other templates, or
+// with literal source ARNs, are not known when generating the alarms of a queue (see findEventSources).
+func withoutConsumedQueueAlarms(alarms []*Alarm, consumedQueues map[string]struct{}) []*Alarm {
+	kept := alarms[:0]
+	for _, alarm := range alarms {
+		props := &alarm.Properties
+		if alarm.resourceType == "AWS::SQS::Queue" && props.MetricName == "ApproximateAgeOfOldestMessage" &&
+			len(props.Dimensions) == 1 {
+
+			if queueName, isLiteral := props.Dimensions[0].Value.(string); isLiteral {
+				if _, isConsumed := consumedQueues[queueName]; isConsumed {
+					continue
+				}
+			}
+		}
+		kept = append(kept, alarm)
+	}
+	return kept
+}
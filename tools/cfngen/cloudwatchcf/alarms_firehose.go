@@ -0,0 +1,60 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+)
+
+type FirehoseAlarm struct {
+	Alarm
+}
+
+func NewFirehoseAlarm(logicalID, alarmType, metricName, message string, resource map[interface{}]interface{},
+	config *Config) (alarm *FirehoseAlarm) {
+
+	const (
+		metricDimension = "DeliveryStreamName"
+		metricNamespace = "AWS/Firehose"
+	)
+	streamName, streamDimension := getResourceName(metricDimension, logicalID, resource) // a Ref returns the name
+	alarmName := AlarmName(alarmType, streamName)
+	alarm = &FirehoseAlarm{
+		Alarm: *NewAlarm(streamName, alarmName,
+			fmt.Sprintf("Firehose %s %s. See: %s#%s", streamName, message, documentationURL, streamName),
+			config.snsTopicArn),
+	}
+	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: streamDimension}})
+	return alarm
+}
+
+func generateFirehoseAlarms(logicalID string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+	// the oldest record not yet delivered, buffering is at most 15 min so older data is stuck
+	alarms = append(alarms, NewFirehoseAlarm(logicalID, "FirehoseStaleData", "DeliveryToS3.DataFreshness",
+		"is not delivering data", resource, config).MaxSecondsThreshold(60*15, 60*5))
+
+	// failed deliveries are retried but may eventually be dropped, the ratio of successful deliveries is 1 normally
+	alarms = append(alarms, NewFirehoseAlarm(logicalID, "FirehoseDeliveryError", "DeliveryToS3.Success",
+		"is failing to deliver data", resource, config).AverageNoUnitsThreshold(1, 60*5).BelowThreshold())
+
+	alarms = append(alarms, NewFirehoseAlarm(logicalID, "FirehoseThrottles", "ThrottledRecords",
+		"is being throttled", resource, config).SumCountThreshold(0, 60*5))
+
+	return alarms
+}
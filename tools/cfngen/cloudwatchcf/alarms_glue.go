@@ -0,0 +1,69 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+)
+
+type GlueJobAlarm struct {
+	Alarm
+}
+
+// NewGlueJobAlarm creates an alarm on a Glue job metric aggregated over all runs of the job, Glue reports the metrics
+// per JobRunId and Type (count, gauge) with "ALL" for the aggregate
+func NewGlueJobAlarm(logicalID, alarmType, metricName, message string, extraDimensions []MetricDimension,
+	resource map[interface{}]interface{}, config *Config) (alarm *GlueJobAlarm) {
+
+	const (
+		metricDimension = "JobName"
+		metricNamespace = "Glue"
+	)
+	jobName, jobDimension := getResourceName("Name", logicalID, resource) // a Ref to a job returns the name
+	alarmName := AlarmName(alarmType, jobName)
+	alarm = &GlueJobAlarm{
+		Alarm: *NewAlarm(jobName, alarmName,
+			fmt.Sprintf("Glue job %s %s. See: %s#%s", jobName, message, documentationURL, jobName),
+			config.snsTopicArn),
+	}
+	dimensions := append([]MetricDimension{
+		{Name: metricDimension, Value: jobDimension},
+		{Name: "JobRunId", Value: "ALL"},
+		{Name: "Type", Value: "count"},
+	}, extraDimensions...)
+	alarm.Alarm.Metric(metricNamespace, metricName, dimensions)
+	return alarm
+}
+
+// generateGlueJobAlarms generates alarms only for jobs tagged panther:monitoring=enabled, not every job warrants paging
+func generateGlueJobAlarms(logicalID string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+	if getResourceTags(resource)[monitoringTagKey] != monitoringTagEnabled {
+		return nil
+	}
+
+	// failed Spark tasks
+	alarms = append(alarms, NewGlueJobAlarm(logicalID, "GlueJobFailedTasks", "glue.driver.aggregate.numFailedTasks",
+		"has failing tasks", nil, resource, config).SumCountThreshold(0, 60*5))
+
+	// failed job runs, reported when the job runs with --enable-observability-metrics
+	alarms = append(alarms, NewGlueJobAlarm(logicalID, "GlueJobError", "glue.error.ALL", "has failing runs",
+		[]MetricDimension{{Name: "ObservabilityGroup", Value: "error"}}, resource, config).SumCountThreshold(0, 60*5))
+
+	return alarms
+}
@@ -0,0 +1,63 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+)
+
+const defaultKinesisIteratorAgeThreshold float32 = 1000 * 60 * 5 // msec, nothing should be more than 5min behind
+
+type KinesisAlarm struct {
+	Alarm
+}
+
+func NewKinesisAlarm(streamName string, streamDimension interface{}, alarmType, metricName, message string,
+	config *Config) (alarm *KinesisAlarm) {
+
+	const (
+		metricDimension = "StreamName"
+		metricNamespace = "AWS/Kinesis"
+	)
+	alarmName := AlarmName(alarmType, streamName)
+	alarm = &KinesisAlarm{
+		Alarm: *NewAlarm(streamName, alarmName,
+			fmt.Sprintf("Kinesis stream %s %s. See: %s#%s", streamName, message, documentationURL, streamName),
+			config.snsTopicArn),
+	}
+	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: streamDimension}})
+	return alarm
+}
+
+func generateKinesisAlarms(logicalID string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+	streamName, streamDimension := getResourceName("Name", logicalID, resource)
+
+	// consumers falling behind
+	alarms = append(alarms, NewKinesisAlarm(streamName, streamDimension, "KinesisIteratorAge", "GetRecords.IteratorAgeMilliseconds",
+		"has consumers not reading at the expected rate", config).
+		MaxMillisecondsThreshold(config.kinesisIteratorAgeThreshold, 60*5))
+
+	// throttles
+	alarms = append(alarms, NewKinesisAlarm(streamName, streamDimension, "KinesisReadThrottles", "ReadProvisionedThroughputExceeded",
+		"is throttling reads", config).SumCountThreshold(0, 60*5))
+	alarms = append(alarms, NewKinesisAlarm(streamName, streamDimension, "KinesisWriteThrottles", "WriteProvisionedThroughputExceeded",
+		"is throttling writes", config).SumCountThreshold(0, 60*5))
+
+	return alarms
+}
@@ -22,19 +22,25 @@ import (
 	"fmt"
 )
 
+// the CF defaults when a function does not set MemorySize (MB) or Timeout (sec)
+const (
+	defaultLambdaMemorySize float32 = 128
+	defaultLambdaTimeout    float32 = 3
+)
+
 type LambdaAlarm struct {
 	Alarm
 	lambdaName string
 }
 
-func NewLambdaAlarm(alarmType, metricName, message string, resource map[interface{}]interface{},
+func NewLambdaAlarm(logicalID, alarmType, metricName, message string, resource map[interface{}]interface{},
 	config *Config) (alarm *LambdaAlarm) {
 
 	const (
 		metricDimension = "FunctionName"
 		metricNamespace = "AWS/Lambda"
 	)
-	lambdaName := getResourceProperty(metricDimension, resource)
+	lambdaName, lambdaDimension := getResourceName(metricDimension, logicalID, resource)
 	alarmName := AlarmName(alarmType, lambdaName)
 	alarm = &LambdaAlarm{
 		Alarm: *NewAlarm(lambdaName, alarmName,
@@ -42,63 +48,100 @@ func NewLambdaAlarm(alarmType, metricName, message string, resource map[interfac
 			config.snsTopicArn),
 		lambdaName: lambdaName,
 	}
-	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: alarm.lambdaName}})
+	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: lambdaDimension}})
 	return alarm
 }
 
+// NewLambdaErrorRateAlarm creates an alarm on the percent of invocations failing, the rate is a metric math expression
+func NewLambdaErrorRateAlarm(logicalID string, resource map[interface{}]interface{}, config *Config) *Alarm {
+	alarm := NewLambdaAlarm(logicalID, "LambdaErrorRate", "Errors",
+		fmt.Sprintf("has more than %g%% of invocations failing", config.lambdaErrorRateThreshold), resource, config)
+	metric := func(metricName string) Metric {
+		return Metric{Namespace: alarm.Properties.Namespace, MetricName: metricName, Dimensions: alarm.Properties.Dimensions}
+	}
+	return alarm.SumCountThreshold(config.lambdaErrorRateThreshold, 60*5).
+		MetricMath("100 * errors / invocations", "ErrorRate", map[string]Metric{
+			"errors":      metric("Errors"),
+			"invocations": metric("Invocations"),
+		})
+}
+
 type LambdaMetricFilterAlarm struct {
 	LambdaAlarm
 }
 
-func NewLambdaMetricFilterAlarm(alarmType, metricName, message string, resource map[interface{}]interface{},
+func NewLambdaMetricFilterAlarm(logicalID, alarmType, metricName, message string, resource map[interface{}]interface{},
 	config *Config) (alarm *LambdaMetricFilterAlarm) {
 
 	alarm = &LambdaMetricFilterAlarm{
-		LambdaAlarm: *NewLambdaAlarm(alarmType, "", message, resource, config),
+		LambdaAlarm: *NewLambdaAlarm(logicalID, alarmType, "", message, resource, config),
 	}
-	alarm.Alarm.Metric(metricFilterNamespace, LambdaMetricFilterName(alarm.lambdaName, metricName), []MetricDimension{})
+	alarm.Alarm.Metric(config.metricNamespace(), LambdaMetricFilterName(alarm.lambdaName, metricName), []MetricDimension{})
 	return alarm
 }
 
-func generateLambdaAlarms(resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+func generateLambdaAlarms(logicalID string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
 	// errors
-	alarms = append(alarms, NewLambdaAlarm("LambdaErrors", "Errors",
+	alarms = append(alarms, NewLambdaAlarm(logicalID, "LambdaErrors", "Errors",
 		"is failing", resource, config).SumCountThreshold(0, 60*5))
 
+	// errors relative to traffic, for functions where a few errors are expected
+	if config.lambdaErrorRateThreshold > 0 {
+		alarms = append(alarms, NewLambdaErrorRateAlarm(logicalID, resource, config))
+	}
+
 	// throttles
-	alarms = append(alarms, NewLambdaAlarm("LambdaThrottles", "Throttles",
+	alarms = append(alarms, NewLambdaAlarm(logicalID, "LambdaThrottles", "Throttles",
 		"is being throttled", resource, config).SumCountThreshold(5, 60*5) /* tolerate a few throttles before alarming */)
 
 	// errors from metric filter (application logs)
 	// NOTE: it is important to not set units because the metric filter values have no units
-	alarms = append(alarms, NewLambdaMetricFilterAlarm("LambdaApplicationErrors", lambdaErrorsMetricFilterName,
-		"is failing", resource, config).SumNoUnitsThreshold(0, 60*5))
+	if lambdaHasErrorsMetricFilter(logicalID, resource, config) {
+		alarms = append(alarms, NewLambdaMetricFilterAlarm(logicalID, "LambdaApplicationErrors", lambdaErrorsMetricFilterName,
+			"is failing", resource, config).SumNoUnitsThreshold(0, 60*5))
+	}
 
 	// warns from metric filter (application logs)
 	// NOTE: it is important to not set units because the metric filter values have no units
-	alarms = append(alarms, NewLambdaMetricFilterAlarm("LambdaApplicationWarns", lambdaWarnsMetricFilterName,
-		"is warning", resource, config).SumNoUnitsThreshold(5, 60*5) /* tolerate a few warnings before alarming */)
+	if lambdaRuntimeKnown(resource) {
+		alarms = append(alarms, NewLambdaMetricFilterAlarm(logicalID, "LambdaApplicationWarns", lambdaWarnsMetricFilterName,
+			"is warning", resource, config).SumNoUnitsThreshold(5, 60*5) /* tolerate a few warnings before alarming */)
+	}
+
+	// panics from metric filter (application logs)
+	// NOTE: it is important to not set units because the metric filter values have no units
+	if lambdaRuntimeHasMetricFilter(resource, lambdaPanicsMetricFilterName) {
+		alarms = append(alarms, NewLambdaMetricFilterAlarm(logicalID, "LambdaApplicationPanics", lambdaPanicsMetricFilterName,
+			"is panicking", resource, config).SumNoUnitsThreshold(0, 60*5))
+	}
 
 	// high water mark memory warning from metric filter
 	const memorySizeKey = "MemorySize"
-	lambdaMem := getResourceFloat32Property(memorySizeKey, resource)
+	lambdaMem := getResourceFloat32PropertyDefault(memorySizeKey, defaultLambdaMemorySize, resource)
 	const highMemThreshold float32 = 0.9
 	highMemMessage := fmt.Sprintf("is using more than %d%% of available memory (%dMB)", (int)(highMemThreshold*100.0), (int)(lambdaMem))
 	// NOTE: it is important to not set units because the metric filter values have no units
-	alarms = append(alarms, NewLambdaMetricFilterAlarm("LambdaHighMemoryWarn", lambdaMemoryMetricFilterName,
+	alarms = append(alarms, NewLambdaMetricFilterAlarm(logicalID, "LambdaHighMemoryWarn", lambdaMemoryMetricFilterName,
 		// 15 min sustained duration
 		highMemMessage, resource, config).MaxNoUnitsThreshold(lambdaMem*highMemThreshold, 60*5).EvaluationPeriods(3))
 
 	// high water mark execution time warning from standard metric
 	const timeoutKey = "Timeout"
-	lambdaTimeout := getResourceFloat32Property(timeoutKey, resource)
+	lambdaTimeout := getResourceFloat32PropertyDefault(timeoutKey, defaultLambdaTimeout, resource)
 	lambdaTimeout *= 1000 // to milliseconds to match metric units
 	const highTimeThreshold float32 = 0.9
 	timeOutMessage := fmt.Sprintf("is using more than %d%% of available execution time (%dmsec)",
 		(int)(highTimeThreshold*100.0), (int)(lambdaTimeout))
-	alarms = append(alarms, NewLambdaAlarm("LambdaHighExecutionTimeWarn", "Duration",
+	durationAlarm := NewLambdaAlarm(logicalID, "LambdaHighExecutionTimeWarn", "Duration",
 		// 15 min sustained duration
-		timeOutMessage, resource, config).MaxMillisecondsThreshold(lambdaTimeout*highTimeThreshold, 60*5).EvaluationPeriods(3))
+		timeOutMessage, resource, config).MaxMillisecondsThreshold(lambdaTimeout*highTimeThreshold, 60*5).EvaluationPeriods(3)
+	if config.lambdaDurationStatistic != "" {
+		durationAlarm.ExtendedStatistic(config.lambdaDurationStatistic)
+	}
+	alarms = append(alarms, durationAlarm)
+
+	// reserved and provisioned concurrency
+	alarms = append(alarms, generateLambdaConcurrencyAlarms(logicalID, resource, config)...)
 
 	return alarms
 }
@@ -0,0 +1,151 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	lambdaAliasType = "AWS::Lambda::Alias"
+
+	// alarm when the concurrency is above this fraction of the ceiling, before invocations are throttled
+	highConcurrencyThreshold float32 = 0.8
+	// ProvisionedConcurrencyUtilization is a fraction of the provisioned concurrency in use
+	highProvisionedConcurrencyThreshold float32 = 0.9
+)
+
+// LambdaConcurrencyLimit configures alarming when the concurrent executions of all the functions in the account and
+// region approach the limit (e.g., the service quota of 1000), 0 for no account alarm (the default).
+// This alarm is not per resource so it is generated once with the resource alarms.
+func (config *Config) LambdaConcurrencyLimit(limit float32) *Config {
+	config.lambdaConcurrencyLimit = limit
+	return config
+}
+
+// generateLambdaAccountAlarms returns the alarm on the concurrent executions of the account, if configured
+func generateLambdaAccountAlarms(config *Config) (alarms []*Alarm, err error) {
+	if config.lambdaConcurrencyLimit <= 0 {
+		return nil, nil
+	}
+	// account metrics have no dimensions, the resource in the runbooks is the service
+	const resourceName = "Lambda"
+	alarm := NewAlarm(resourceName, AlarmName("LambdaAccountConcurrency", resourceName),
+		fmt.Sprintf("Lambda concurrent executions in the account are above %d%% of the limit (%d). See: %s#%s",
+			(int)(highConcurrencyThreshold*100.0), (int)(config.lambdaConcurrencyLimit), documentationURL, resourceName),
+		config.snsTopicArn).
+		Metric("AWS/Lambda", "ConcurrentExecutions", []MetricDimension{}).
+		MaxCountThreshold(config.lambdaConcurrencyLimit*highConcurrencyThreshold, 60).For(5 * time.Minute)
+	if err = config.configure(alarm, "", nil); err != nil {
+		return nil, err
+	}
+	return append(alarms, alarm), nil
+}
+
+// generateLambdaConcurrencyAlarms alarms on the concurrent executions of a function approaching its reserved
+// concurrency, the ceiling above which its invocations are throttled. Functions without reserved concurrency share the
+// account limit (see LambdaConcurrencyLimit). The threshold can be replaced with an override of ConcurrentExecutions.
+func generateLambdaConcurrencyAlarms(logicalID string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+	if reserved, ok := literalConcurrency(getResourceNestedProperty(resource, "ReservedConcurrentExecutions")); ok {
+		alarms = append(alarms, NewLambdaAlarm(logicalID, "LambdaHighConcurrency", "ConcurrentExecutions",
+			fmt.Sprintf("is using more than %d%% of its reserved concurrency (%d)",
+				(int)(highConcurrencyThreshold*100.0), (int)(reserved)), resource, config).
+			MaxCountThreshold(reserved*highConcurrencyThreshold, 60).For(5*time.Minute))
+	}
+
+	// SAM publishes an alias for the function, with the provisioned concurrency
+	aliasName, isLiteral := getResourceNestedProperty(resource, "AutoPublishAlias").(string)
+	if !isLiteral || !hasProvisionedConcurrency(resource) {
+		return alarms
+	}
+	functionName, functionDimension := getResourceName("FunctionName", logicalID, resource)
+	return append(alarms, provisionedConcurrencyAlarms(logicalID, functionName, functionDimension, aliasName,
+		aliasName, config)...)
+}
+
+// generateLambdaAliasAlarms alarms on the use of the provisioned concurrency of a function alias, when all of it is in
+// use invocations spill over to on demand instances with cold starts. Aliases without provisioned concurrency are skipped.
+func generateLambdaAliasAlarms(logicalID string, resource map[interface{}]interface{},
+	resources map[string]map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+
+	if !hasProvisionedConcurrency(resource) {
+		return nil
+	}
+	functionName, functionDimension := eventSourceFunction(logicalID, getResourceNestedProperty(resource, "FunctionName"),
+		resources)
+	aliasName, aliasDimension := getResourceName("Name", logicalID, resource)
+	return provisionedConcurrencyAlarms(logicalID, functionName, functionDimension, aliasName, aliasDimension, config)
+}
+
+// provisionedConcurrencyAlarms returns the alarms on the provisioned concurrency of the alias of the function, the
+// metrics have the alias as the Resource dimension, e.g., my-function:live
+func provisionedConcurrencyAlarms(logicalID, functionName string, functionDimension interface{}, aliasName string,
+	aliasDimension interface{}, config *Config) (alarms []*Alarm) {
+
+	resourceName := functionName + ":" + aliasName
+	var resourceDimension interface{} = resourceName
+	_, isLiteralFunction := functionDimension.(string)
+	_, isLiteralAlias := aliasDimension.(string)
+	if !isLiteralFunction || !isLiteralAlias { // resolved at deploy time
+		resourceDimension = map[string]interface{}{
+			"Fn::Join": []interface{}{"", []interface{}{functionDimension, ":", aliasDimension}},
+		}
+	}
+	dimensions := []MetricDimension{
+		{Name: "FunctionName", Value: functionDimension},
+		{Name: "Resource", Value: resourceDimension},
+	}
+	alarm := func(alarmType, metricName, message string) *Alarm {
+		alarm := NewAlarm(logicalID, AlarmName(alarmType, resourceName),
+			fmt.Sprintf("Lambda %s %s. See: %s#%s", resourceName, message, documentationURL, functionName),
+			config.snsTopicArn)
+		return alarm.Metric("AWS/Lambda", metricName, dimensions)
+	}
+
+	// 15 min sustained duration, short bursts above the provisioned concurrency are expected
+	alarms = append(alarms, alarm("LambdaProvisionedConcurrency", "ProvisionedConcurrencyUtilization",
+		fmt.Sprintf("is using more than %d%% of its provisioned concurrency", (int)(highProvisionedConcurrencyThreshold*100.0))).
+		MaxNoUnitsThreshold(highProvisionedConcurrencyThreshold, 60*5).EvaluationPeriods(3))
+
+	alarms = append(alarms, alarm("LambdaProvisionedConcurrencySpillover", "ProvisionedConcurrencySpilloverInvocations",
+		"has invocations beyond its provisioned concurrency").
+		SumCountThreshold(0, 60*5))
+	return alarms
+}
+
+// hasProvisionedConcurrency returns true if the alias or SAM function sets a provisioned concurrency
+func hasProvisionedConcurrency(resource map[interface{}]interface{}) bool {
+	return getResourceNestedProperty(resource, "ProvisionedConcurrencyConfig", "ProvisionedConcurrentExecutions") != nil
+}
+
+// literalConcurrency returns the concurrency if it is set with a constant value, it is not known for a CF intrinsic
+func literalConcurrency(value interface{}) (concurrency float32, ok bool) {
+	switch val := value.(type) {
+	case int:
+		return (float32)(val), true
+	case float64:
+		return (float32)(val), true
+	case string:
+		parsed, err := strconv.ParseFloat(val, 32)
+		return (float32)(parsed), err == nil
+	}
+	return 0, false
+}
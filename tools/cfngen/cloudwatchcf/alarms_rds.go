@@ -0,0 +1,89 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+)
+
+const (
+	rdsInstanceType = "AWS::RDS::DBInstance"
+
+	gibibyte float32 = 1024 * 1024 * 1024
+
+	defaultRDSFreeStorageThreshold         = 2 * gibibyte // when the allocated storage is not known
+	rdsFreeStorageFraction         float32 = 0.1          // of the allocated storage
+	defaultRDSFreeMemoryThreshold          = gibibyte / 4
+	defaultRDSCPUThreshold         float32 = 85  // percent
+	defaultRDSConnectionsThreshold         = 200 // depends on the instance class, use overrides to tune
+)
+
+type RDSAlarm struct {
+	Alarm
+}
+
+func NewRDSAlarm(logicalID, resourceType, alarmType, metricName, message string, resource map[interface{}]interface{},
+	config *Config) (alarm *RDSAlarm) {
+
+	const metricNamespace = "AWS/RDS"
+	metricDimension, kind := "DBClusterIdentifier", "cluster"
+	if resourceType == rdsInstanceType {
+		metricDimension, kind = "DBInstanceIdentifier", "instance"
+	}
+	dbName, dbDimension := getResourceName(metricDimension, logicalID, resource) // a Ref returns the identifier
+	alarmName := AlarmName(alarmType, dbName)
+	alarm = &RDSAlarm{
+		Alarm: *NewAlarm(dbName, alarmName,
+			fmt.Sprintf("RDS %s %s %s. See: %s#%s", kind, dbName, message, documentationURL, dbName),
+			config.snsTopicArn),
+	}
+	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: dbDimension}})
+	return alarm
+}
+
+func generateRDSAlarms(logicalID, resourceType string, resource map[interface{}]interface{},
+	config *Config) (alarms []*Alarm) {
+
+	// load, sustained for 15 min
+	alarms = append(alarms, NewRDSAlarm(logicalID, resourceType, "RDSHighCPU", "CPUUtilization",
+		"is using too much CPU", resource, config).
+		AveragePercentThreshold(defaultRDSCPUThreshold, 60*5).EvaluationPeriods(3))
+
+	alarms = append(alarms, NewRDSAlarm(logicalID, resourceType, "RDSHighConnections", "DatabaseConnections",
+		"has too many connections", resource, config).MaxCountThreshold(defaultRDSConnectionsThreshold, 60*5))
+
+	// running out of resources alarms when the free amount falls below the threshold
+	alarms = append(alarms, NewRDSAlarm(logicalID, resourceType, "RDSLowMemory", "FreeableMemory",
+		"is running out of memory", resource, config).MinBytesThreshold(defaultRDSFreeMemoryThreshold, 60*5))
+
+	if resourceType == rdsInstanceType {
+		freeStorageThreshold := defaultRDSFreeStorageThreshold
+		if getResourceNestedProperty(resource, "AllocatedStorage") != nil {
+			freeStorageThreshold = getResourceFloat32Property("AllocatedStorage", resource) * gibibyte * rdsFreeStorageFraction
+		}
+		alarms = append(alarms, NewRDSAlarm(logicalID, resourceType, "RDSLowStorage", "FreeStorageSpace",
+			"is running out of storage", resource, config).MinBytesThreshold(freeStorageThreshold, 60*5))
+	} else {
+		// Aurora storage grows with the cluster volume, the instances have local storage for temporary data
+		alarms = append(alarms, NewRDSAlarm(logicalID, resourceType, "RDSLowLocalStorage", "FreeLocalStorage",
+			"is running out of local storage", resource, config).MinBytesThreshold(defaultRDSFreeStorageThreshold, 60*5))
+	}
+
+	return alarms
+}
@@ -0,0 +1,64 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+type StateMachineAlarm struct {
+	Alarm
+}
+
+func NewStateMachineAlarm(logicalID, alarmType, metricName, message string, resource map[interface{}]interface{},
+	config *Config) (alarm *StateMachineAlarm) {
+
+	const (
+		metricDimension = "StateMachineArn"
+		metricNamespace = "AWS/States"
+	)
+	stateMachineName, _ := getResourceName("StateMachineName", logicalID, resource)
+	alarmName := AlarmName(alarmType, stateMachineName)
+	alarm = &StateMachineAlarm{
+		Alarm: *NewAlarm(stateMachineName, alarmName,
+			fmt.Sprintf("StateMachine %s %s. See: %s#%s", stateMachineName, message, documentationURL, stateMachineName),
+			config.snsTopicArn),
+	}
+	// the arn is only known at deploy time, a Ref to a state machine returns the arn
+	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: cfngen.Ref{Ref: logicalID}}})
+	return alarm
+}
+
+func generateStateMachineAlarms(logicalID string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+	// failures
+	alarms = append(alarms, NewStateMachineAlarm(logicalID, "StateMachineFailed", "ExecutionsFailed",
+		"has failed executions", resource, config).SumCountThreshold(0, 60*5))
+
+	// timeouts
+	alarms = append(alarms, NewStateMachineAlarm(logicalID, "StateMachineTimedOut", "ExecutionsTimedOut",
+		"has timed out executions", resource, config).SumCountThreshold(0, 60*5))
+
+	// throttles
+	alarms = append(alarms, NewStateMachineAlarm(logicalID, "StateMachineThrottled", "ExecutionThrottled",
+		"is being throttled", resource, config).SumCountThreshold(0, 60*5))
+
+	return alarms
+}
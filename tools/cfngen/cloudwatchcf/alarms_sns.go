@@ -20,33 +20,44 @@ package cloudwatchcf
 
 import (
 	"fmt"
+
+	"github.com/panther-labs/panther/tools/cfngen"
 )
 
 type SNSAlarm struct {
 	Alarm
 }
 
-func NewSNSAlarm(alarmType, metricName, message string, resource map[interface{}]interface{},
+func NewSNSAlarm(logicalID, alarmType, metricName, message string, resource map[interface{}]interface{},
 	config *Config) (alarm *SNSAlarm) {
 
 	const (
 		metricDimension = "TopicName"
 		metricNamespace = "AWS/SNS"
 	)
-	topicName := getResourceProperty(metricDimension, resource)
+	topicName, topicDimension := getResourceName(metricDimension, logicalID, resource)
+	if _, isRef := topicDimension.(cfngen.Ref); isRef {
+		// the name is generated by CF, a Ref to a topic returns the arn so use the TopicName attribute
+		topicDimension = map[string]interface{}{"Fn::GetAtt": []interface{}{logicalID, "TopicName"}}
+	}
 	alarmName := AlarmName(alarmType, topicName)
 	alarm = &SNSAlarm{
 		Alarm: *NewAlarm(topicName, alarmName,
 			fmt.Sprintf("SNS topic %s %s. See: %s#%s", topicName, message, documentationURL, topicName),
 			config.snsTopicArn),
 	}
-	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: topicName}})
+	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: topicDimension}})
 	return alarm
 }
 
-func generateSNSAlarms(resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
+func generateSNSAlarms(logicalID string, resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
 	// errors
-	alarms = append(alarms, NewSNSAlarm("SNSError", "NumberOfNotificationsFailed", "is failing",
+	alarms = append(alarms, NewSNSAlarm(logicalID, "SNSError", "NumberOfNotificationsFailed", "is failing",
+		resource, config).SumCountThreshold(0, 60*5))
+
+	// messages dropped because their attributes do not match the subscription filter policies
+	alarms = append(alarms, NewSNSAlarm(logicalID, "SNSFilteredOutInvalidAttributes",
+		"NumberOfNotificationsFilteredOut-InvalidAttributes", "is dropping messages with invalid attributes",
 		resource, config).SumCountThreshold(0, 60*5))
 
 	return alarms
@@ -21,14 +21,16 @@ package cloudwatchcf
 import (
 	"fmt"
 	"strings"
+
+	"github.com/panther-labs/panther/tools/cfngen"
 )
 
 type SQSAlarm struct {
 	Alarm
 }
 
-func NewSQSAlarm(queueName, alarmType, metricName, message string, resource map[interface{}]interface{},
-	config *Config) (alarm *SQSAlarm) {
+func NewSQSAlarm(queueName string, queueDimension interface{}, alarmType, metricName, message string,
+	resource map[interface{}]interface{}, config *Config) (alarm *SQSAlarm) {
 
 	const (
 		metricDimension = "QueueName"
@@ -40,25 +42,85 @@ func NewSQSAlarm(queueName, alarmType, metricName, message string, resource map[
 			fmt.Sprintf("SQS queue %s %s. See: %s#%s", queueName, message, documentationURL, queueName),
 			config.snsTopicArn),
 	}
-	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: queueName}})
+	alarm.Alarm.Metric(metricNamespace, metricName, []MetricDimension{{Name: metricDimension, Value: queueDimension}})
 	return alarm
 }
 
-func generateSQSAlarms(resource map[interface{}]interface{}, config *Config) (alarms []*Alarm) {
-	queueName := getResourceProperty("QueueName", resource)
+// sqsQueueName returns the name of the queue and the value to use as the QueueName dimension. A Ref to a queue returns
+// the url, so a queue named by CF is dimensioned by its QueueName attribute.
+func sqsQueueName(logicalID string, resource map[interface{}]interface{}) (queueName string, queueDimension interface{}) {
+	queueName, queueDimension = getResourceName("QueueName", logicalID, resource)
+	if _, isRef := queueDimension.(cfngen.Ref); isRef {
+		queueDimension = map[string]interface{}{"Fn::GetAtt": []interface{}{logicalID, "QueueName"}}
+	}
+	return queueName, queueDimension
+}
+
+func generateSQSAlarms(logicalID string, resource map[interface{}]interface{}, index *templateIndex,
+	config *Config) (alarms []*Alarm) {
+
+	queueName, queueDimension := sqsQueueName(logicalID, resource)
 
 	// DLQ qs are special, we alarm on ANY data in q
-	if strings.HasSuffix(queueName, "-dlq") {
-		referenceQueue := strings.Replace(queueName, "-dlq", "", -1)
+	if isDeadLetterQueue(logicalID, queueName, index.deadLetterQueues) {
+		referenceQueue, found := index.deadLetterQueues[logicalID]
+		if !found {
+			referenceQueue = strings.Replace(queueName, "-dlq", "", -1)
+		}
 		// NOTE: this metric appears to have no units
-		alarms = append(alarms, NewSQSAlarm(queueName, "SQSDeadLetters", "ApproximateNumberOfMessagesVisible",
-			"has failed items from"+referenceQueue, resource, config).SumNoUnitsThreshold(0, 60*5))
-	} else { // regular q's
+		alarms = append(alarms, NewSQSAlarm(queueName, queueDimension, "SQSDeadLetters", "ApproximateNumberOfMessagesVisible",
+			"has failed items from "+referenceQueue, resource, config).SumNoUnitsThreshold(0, 60*5))
+	} else if _, consumed := index.eventSources[logicalID]; !consumed { // regular q's, those consumed by a mapping alarm with it
 		// nothing in our queues should be older than 5min
 		const tooOldSec float32 = 60.0 * 5.0
-		alarms = append(alarms, NewSQSAlarm(queueName, "SQSTooOld", "ApproximateAgeOfOldestMessage",
+		alarms = append(alarms, NewSQSAlarm(queueName, queueDimension, "SQSTooOld", "ApproximateAgeOfOldestMessage",
 			"has items not being processed at the expected rate", resource, config).MaxSecondsThreshold(tooOldSec, 60*5))
 	}
 
 	return alarms
 }
+
+// isDeadLetterQueue returns true if the queue is a DLQ by naming convention or because it is the
+// redrive target of another queue (as returned by findDeadLetterQueues)
+func isDeadLetterQueue(logicalID, queueName string, deadLetterQueues map[string]string) bool {
+	if strings.HasSuffix(queueName, "-dlq") || strings.HasSuffix(logicalID, "DeadLetterQueue") {
+		return true
+	}
+	_, found := deadLetterQueues[logicalID]
+	return found
+}
+
+// findDeadLetterQueues returns the logical ids of the queues that are the redrive target of another queue,
+// mapped to the name of the queue they receive failed items from
+func findDeadLetterQueues(resources map[string]map[interface{}]interface{}) (deadLetterQueues map[string]string) {
+	queueIDs := make(map[string][]string) // by queue name, for redrive targets with a literal arn
+	for logicalID, resource := range resources {
+		if resource["Type"] == "AWS::SQS::Queue" {
+			queueName, _ := getResourceName("QueueName", logicalID, resource)
+			queueIDs[queueName] = append(queueIDs[queueName], logicalID)
+		}
+	}
+
+	deadLetterQueues = make(map[string]string)
+	for logicalID, resource := range resources {
+		if resource["Type"] != "AWS::SQS::Queue" {
+			continue
+		}
+		target := getResourceNestedProperty(resource, "RedrivePolicy", "deadLetterTargetArn")
+		if target == nil {
+			continue
+		}
+		sourceQueueName, _ := getResourceName("QueueName", logicalID, resource)
+		if targetID := getAttLogicalID(target); targetID != "" {
+			deadLetterQueues[targetID] = sourceQueueName
+			continue
+		}
+		// a literal arn, find the queue by name
+		if targetArn, ok := target.(string); ok {
+			for _, targetID := range queueIDs[targetArn[strings.LastIndex(targetArn, ":")+1:]] {
+				deadLetterQueues[targetID] = sourceQueueName
+			}
+		}
+	}
+	return deadLetterQueues
+}
@@ -19,22 +19,891 @@ package cloudwatchcf
  */
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/tools/cfngen"
 )
 
 func TestGenerateAlarms(t *testing.T) {
-	stackOutputs := map[string]string{
-		"WebApplicationLoadBalancerFullName": "testLoadbalancer",
-		"WebApplicationGraphqlApiId":         "testGraphqlId",
+	requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", testStackOutputs()), "./testdata/cf.yml", "./testdata/generated_test_alarms.json")
+}
+
+func TestGenerateAlarmsOverrides(t *testing.T) {
+	stackOutputs := testStackOutputs()
+	overrides, err := ReadOverrides("./testdata/overrides.json")
+	require.NoError(t, err)
+	overriddenAlarms, _, err := GenerateAlarmsWithConfig(NewConfig("my-sns-topic-arn", stackOutputs).Overrides(overrides),
+		"./testdata/cf.yml")
+	require.NoError(t, err)
+	defaultAlarms, _, err := GenerateAlarms("my-sns-topic-arn", stackOutputs, "./testdata/cf.yml")
+	require.NoError(t, err)
+	require.Equal(t, len(defaultAlarms), len(overriddenAlarms))
+	defaultAlarmsByName := make(map[string]*Alarm)
+	for _, alarm := range defaultAlarms {
+		defaultAlarmsByName[alarm.Properties.AlarmName] = alarm
+	}
+
+	for _, alarm := range overriddenAlarms {
+		defaultAlarm := defaultAlarmsByName[alarm.Properties.AlarmName]
+		require.NotNil(t, defaultAlarm)
+		switch {
+		case alarm.LogicalID == "Function" && alarm.Properties.MetricName == "Duration":
+			require.Equal(t, float32(300000), *alarm.Properties.Threshold)
+			require.Equal(t, 2, alarm.Properties.EvaluationPeriods)
+			require.Equal(t, defaultAlarm.Properties.Period, alarm.Properties.Period)
+		case alarm.LogicalID == "Function" && alarm.Properties.MetricName == "Errors":
+			require.Equal(t, 60, alarm.Properties.Period)
+			require.Equal(t, defaultAlarm.Properties.Threshold, alarm.Properties.Threshold)
+		default: // no override, keeps defaults
+			require.Equal(t, defaultAlarm, alarm)
+		}
+	}
+
+	// no file, no overrides
+	overrides, err = ReadOverrides("")
+	require.NoError(t, err)
+	require.Nil(t, overrides)
+}
+
+func TestGenerateAlarmsRoutes(t *testing.T) {
+	opsTopic := cfngen.Ref{Ref: "OpsAlarmsTopic"}
+	config := NewConfig("my-sns-topic-arn", nil).Routes(
+		AlarmRoute{TagKey: "panther:alarms", TagValue: "security", Topic: "my-security-sns-topic-arn"},
+		AlarmRoute{ResourcePrefix: "test-ops-", Topic: opsTopic},
+	)
+	alarms, _, err := GenerateAlarmsWithConfig(config, "./testdata/routes.yml")
+	require.NoError(t, err)
+	require.NotEmpty(t, alarms)
+
+	for _, alarm := range alarms {
+		switch alarm.LogicalID {
+		case "Function":
+			expectedActions := []interface{}{"my-security-sns-topic-arn"}
+			require.Equal(t, expectedActions, alarm.Properties.AlarmActions)
+			require.Equal(t, expectedActions, alarm.Properties.OKActions)
+			require.Equal(t, expectedActions, alarm.Properties.InsufficientDataActions)
+		case "Queue":
+			expectedActions := []interface{}{opsTopic}
+			require.Equal(t, expectedActions, alarm.Properties.AlarmActions)
+			require.Equal(t, expectedActions, alarm.Properties.OKActions)
+			require.Equal(t, expectedActions, alarm.Properties.InsufficientDataActions)
+		default: // no route, default topic
+			require.Equal(t, []interface{}{"my-sns-topic-arn"}, alarm.Properties.AlarmActions)
+			require.Empty(t, alarm.Properties.OKActions)
+			require.Empty(t, alarm.Properties.InsufficientDataActions)
+		}
+	}
+}
+
+func TestGenerateNestedStackAlarms(t *testing.T) {
+	template, err := BuildAlarms(NewConfig("my-sns-topic-arn", nil).FollowNestedStacks(true), "./testdata/nested/parent.yml")
+	require.NoError(t, err)
+	cf, err := template.Marshal(JSONFormat)
+	require.NoError(t, err)
+	requireGoldenFile(t, "./testdata/generated_test_nested_alarms.json", cf) // not validated, the nested alarms are not in the parent
+
+	// the remote stack is skipped with a warning
+	require.Equal(t, []Warning{{File: "./testdata/nested/parent.yml", LogicalID: "RemoteStack", Type: "AWS::CloudFormation::Stack",
+		Reason: "the TemplateURL https://s3.amazonaws.com/test-bucket/remote.yml is not a local template"}}, template.Warnings)
+
+	// not followed unless configured
+	alarms, _, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/nested/parent.yml")
+	require.NoError(t, err)
+	for _, alarm := range alarms {
+		require.Equal(t, "Notifications", alarm.LogicalID) // only the topic of the parent
+	}
+}
+
+func TestGenerateNestedStackAlarmsCycle(t *testing.T) {
+	_, err := BuildAlarms(NewConfig("my-sns-topic-arn", nil).FollowNestedStacks(true), "./testdata/nested_cycle/outer.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(),
+		"InnerStack: OuterStack: the template testdata/nested_cycle/outer.yml of the nested stack includes itself")
+	_, err = BuildAlarms(NewConfig("my-sns-topic-arn", nil).FollowNestedStacks(true), "./testdata/nested_cycle/self.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "SelfStack: the template testdata/nested_cycle/self.yml of the nested stack includes itself")
+
+	// not followed unless configured
+	_, err = BuildAlarms(NewConfig("my-sns-topic-arn", nil), "./testdata/nested_cycle/outer.yml")
+	require.NoError(t, err)
+}
+
+func TestGenerateAlarmsIntrinsicNames(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/intrinsics.yml",
+		"./testdata/generated_test_intrinsics_alarms.json").Alarms
+
+	for _, alarm := range alarms {
+		if alarm.Properties.Namespace == "AWS/Lambda" {
+			require.Equal(t, map[string]interface{}{"Fn::Sub": "${AWS::StackName}-log-processor"},
+				alarm.Properties.Dimensions[0].Value)
+		}
 	}
-	_, cf, err := GenerateAlarms("my-sns-topic-arn", stackOutputs, "./testdata/cf.yml")
+}
+
+func TestGenerateKinesisAlarms(t *testing.T) {
+	requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/kinesis.yml", "./testdata/generated_test_kinesis_alarms.json")
+
+	// override iterator age threshold
+	const iteratorAgeThreshold float32 = 1000 * 60 * 60
+	alarms, _, err := GenerateAlarmsWithConfig(NewConfig("my-sns-topic-arn", nil).
+		KinesisIteratorAgeThreshold(iteratorAgeThreshold), "./testdata/kinesis.yml")
 	require.NoError(t, err)
-	const expectedFile = "./testdata/generated_test_alarms.json"
-	// uncomment to make a new expected file
-	// writeTestFile(cf, expectedFile)
-	expectedCf, err := readTestFile(expectedFile)
+	for _, alarm := range alarms {
+		if alarm.Properties.MetricName == "GetRecords.IteratorAgeMilliseconds" {
+			require.Equal(t, iteratorAgeThreshold, *alarm.Properties.Threshold)
+		}
+	}
+}
+
+func TestGenerateStateMachineAlarms(t *testing.T) {
+	requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/sfn.yml", "./testdata/generated_test_sfn_alarms.json")
+}
+
+func TestGenerateDynamoDBAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/ddb.yml",
+		"./testdata/generated_test_ddb_alarms.json").Alarms
+
+	// only the provisioned tables have capacity alarms, and only for the literal capacities
+	capacityMetrics := make(map[string][]string)
+	for _, alarm := range alarms {
+		if alarm.Properties.MetricName == "ConsumedReadCapacityUnits" || alarm.Properties.MetricName == "ConsumedWriteCapacityUnits" {
+			capacityMetrics[alarm.LogicalID] = append(capacityMetrics[alarm.LogicalID], alarm.Properties.MetricName)
+		}
+	}
+	require.Equal(t, map[string][]string{
+		"ParameterTable":   {"ConsumedWriteCapacityUnits"},
+		"ProvisionedTable": {"ConsumedReadCapacityUnits", "ConsumedWriteCapacityUnits"},
+	}, capacityMetrics)
+}
+
+func TestGenerateSQSAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/sqs.yml",
+		"./testdata/generated_test_sqs_alarms.json").Alarms
+
+	var unnamedAlarms int
+	for _, alarm := range alarms {
+		if alarm.LogicalID != "UnnamedQueue" {
+			continue
+		}
+		unnamedAlarms++
+		require.Equal(t, []MetricDimension{{Name: "QueueName", Value: map[string]interface{}{
+			"Fn::GetAtt": []interface{}{"UnnamedQueue", "QueueName"}}}}, alarm.Properties.Dimensions)
+	}
+	require.Equal(t, 1, unnamedAlarms)
+}
+
+func TestIsDeadLetterQueue(t *testing.T) {
+	yamlObj, err := readYaml("./testdata/sqs.yml")
+	require.NoError(t, err)
+	deadLetterQueues := findDeadLetterQueues(getResources(yamlObj))
+	require.Equal(t, map[string]string{"FailedEventsQueue": "test-events"}, deadLetterQueues)
+
+	require.True(t, isDeadLetterQueue("FailedEventsQueue", "test-failed-events", deadLetterQueues)) // redrive target
+	require.True(t, isDeadLetterQueue("Queue", "test-sqs-dlq", deadLetterQueues))                   // name
+	require.True(t, isDeadLetterQueue("DeadLetterQueue", "test-sqs-failures", deadLetterQueues))    // logical id
+	require.False(t, isDeadLetterQueue("EventsQueue", "test-events", deadLetterQueues))
+}
+
+func TestGenerateAnomalyAlarms(t *testing.T) {
+	alarm := NewAlarm("test-lambda", AlarmName("LambdaDurationAnomaly", "test-lambda"), "Lambda test-lambda has unusual duration",
+		"my-sns-topic-arn").Metric("AWS/Lambda", "Duration", []MetricDimension{{Name: "FunctionName", Value: "test-lambda"}}).
+		MaxMillisecondsThreshold(0, 60*5).EvaluationPeriods(3).AnomalyDetection(2)
+	cf, err := cfngen.NewTemplate("Panther Alarms", nil, alarmResources([]*Alarm{alarm}), nil).CloudFormation()
+	require.NoError(t, err)
+	requireGoldenFile(t, "./testdata/generated_test_anomaly_alarms.json", cf)
+
+	// the band cannot be rendered around an expression, so it is an error rather than silently dropped
+	alarm = NewAlarm("test-lambda", AlarmName("LambdaErrorRateAnomaly", "test-lambda"), "Lambda test-lambda has unusual errors",
+		"my-sns-topic-arn").Metric("AWS/Lambda", "Errors", []MetricDimension{{Name: "FunctionName", Value: "test-lambda"}}).
+		MaxNoUnitsThreshold(0, 60*5).MetricMath("100 * errors / invocations", "ErrorRate", map[string]Metric{
+		"errors":      {Namespace: "AWS/Lambda", MetricName: "Errors"},
+		"invocations": {Namespace: "AWS/Lambda", MetricName: "Invocations"},
+	}).AnomalyDetection(2)
+	err = NewConfig("my-sns-topic-arn", nil).configure(alarm, "", nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `anomaly detection is not supported on the metric math expression "100 * errors / invocations"`)
+}
+
+func TestGenerateCompositeAlarms(t *testing.T) {
+	const lambdaName = "test-lambda"
+	dimensions := []MetricDimension{{Name: "FunctionName", Value: lambdaName}}
+	alarms := []*Alarm{
+		NewAlarm(lambdaName, AlarmName("LambdaErrors", lambdaName), "Lambda test-lambda is failing",
+			"my-sns-topic-arn").Metric("AWS/Lambda", "Errors", dimensions).SumCountThreshold(0, 60*5),
+		NewAlarm(lambdaName, AlarmName("LambdaThrottles", lambdaName), "Lambda test-lambda is being throttled",
+			"my-sns-topic-arn").Metric("AWS/Lambda", "Throttles", dimensions).SumCountThreshold(5, 60*5),
+		NewAlarm(lambdaName, AlarmName("LambdaHighExecutionTimeWarn", lambdaName), "Lambda test-lambda is slow",
+			"my-sns-topic-arn").Metric("AWS/Lambda", "Duration", dimensions).MaxMillisecondsThreshold(54000, 60*5),
+	}
+	compositeAlarms, cf, err := GenerateCompositeAlarms(alarms)
+	require.NoError(t, err)
+	require.Len(t, compositeAlarms, 1)
+	for _, alarm := range alarms {
+		require.Empty(t, alarm.Properties.AlarmActions)
+	}
+	requireGoldenFile(t, "./testdata/generated_test_composite_alarms.json", cf)
+}
+
+func TestGenerateCompositeAlarmsSameName(t *testing.T) {
+	alarms, _, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/composite.yml")
+	require.NoError(t, err)
+	compositeAlarms, _, err := GenerateCompositeAlarms(alarms)
+	require.NoError(t, err)
+
+	alarmsByResource := make(map[string]int)
+	for _, alarm := range alarms {
+		alarmsByResource[alarm.LogicalID]++
+	}
+
+	// the resources are named alike, but each has its own composite alarm of its alarms
+	alarmsByComposite := make(map[string]int)
+	for _, compositeAlarm := range compositeAlarms {
+		require.Equal(t, "test-alerts", compositeAlarm.Resource)
+		alarmsByComposite[compositeAlarm.Properties.AlarmName] = len(compositeAlarm.DependsOn)
+	}
+	require.Equal(t, map[string]int{
+		"PantherAlarm-Composite-test-alerts-AlertsFunction": alarmsByResource["AlertsFunction"],
+		"PantherAlarm-Composite-test-alerts-AlertsTopic":    alarmsByResource["AlertsTopic"],
+	}, alarmsByComposite)
+}
+
+func TestValidateAlarms(t *testing.T) {
+	cfFiles := []string{"./testdata/cf.yml", "./testdata/kinesis.yml", "./testdata/sfn.yml", "./testdata/ddb.yml",
+		"./testdata/sqs.yml", "./testdata/intrinsics.yml"}
+	for _, cfFile := range cfFiles {
+		alarms, _, err := GenerateAlarms("my-sns-topic-arn", testStackOutputs(), cfFile)
+		require.NoError(t, err)
+		require.NoError(t, ValidateAlarms(alarms, cfFile), cfFile)
+	}
+}
+
+func TestValidateAlarmsDanglingReference(t *testing.T) {
+	alarms, _, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/sfn.yml")
+	require.NoError(t, err)
+	alarms[0].LogicalID = "TypoStateMachine"
+	alarms[1].Properties.Dimensions[0].Value = cfngen.Ref{Ref: "TypoStateMachine"}
+	alarms[2].Properties.MetricName = "ExecutionsFaild"
+	typoLambda := NewAlarm("test-lambda", AlarmName("LambdaErrors", "test-lambda"), "Lambda test-lambda is failing",
+		"my-sns-topic-arn").Metric("AWS/Lambda", "Errors", []MetricDimension{{Name: "FunctionName", Value: "test-lambdaa"}}).
+		SumCountThreshold(0, 60*5)
+	alarms = append(alarms, typoLambda)
+
+	err = ValidateAlarms(alarms, "./testdata/sfn.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), alarms[0].Properties.AlarmName+": resource TypoStateMachine is not in the template")
+	require.Contains(t, err.Error(), alarms[1].Properties.AlarmName+
+		": dimension StateMachineArn references resource TypoStateMachine which is not in the template")
+	require.Contains(t, err.Error(), alarms[2].Properties.AlarmName+
+		": metric AWS/States/ExecutionsFaild is not known for AWS::StepFunctions::StateMachine")
+	require.Contains(t, err.Error(), typoLambda.Properties.AlarmName+
+		": dimension FunctionName references test-lambdaa which is not in the template")
+}
+
+func TestValidateAlarmsBadThreshold(t *testing.T) {
+	alarms, _, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/sfn.yml")
+	require.NoError(t, err)
+	alarms[0].SumCountThreshold(-1, -60)
+	alarms[1].EvaluationPeriods(0)
+
+	err = ValidateAlarms(alarms, "./testdata/sfn.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), alarms[0].Properties.AlarmName+": period -60 is negative")
+	require.Contains(t, err.Error(), alarms[0].Properties.AlarmName+": threshold -1 is negative")
+	require.Contains(t, err.Error(), alarms[1].Properties.AlarmName+": evaluation periods 0 is less than 1")
+}
+
+func TestGenerateLambdaDurationExtendedStatistic(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", testStackOutputs()).LambdaDurationStatistic("p99")
+	alarms, _, err := GenerateAlarmsWithConfig(config, "./testdata/cf.yml")
+	require.NoError(t, err)
+	var durationAlarm *Alarm
+	for _, alarm := range alarms {
+		if alarm.Properties.MetricName == "Duration" {
+			durationAlarm = alarm
+		}
+	}
+	require.NotNil(t, durationAlarm)
+
+	alarmJSON, err := json.Marshal(durationAlarm)
+	require.NoError(t, err)
+	var alarmCf struct {
+		Properties map[string]interface{}
+	}
+	require.NoError(t, json.Unmarshal(alarmJSON, &alarmCf))
+	require.Equal(t, "p99", alarmCf.Properties["ExtendedStatistic"])
+	require.NotContains(t, alarmCf.Properties, "Statistic")
+	require.NoError(t, ValidateAlarms(alarms, "./testdata/cf.yml"))
+}
+
+func TestGenerateAPIAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/apigw.yml",
+		"./testdata/generated_test_apigw_alarms.json").Alarms
+
+	// client errors are off by default
+	config := NewConfig("my-sns-topic-arn", nil).APIClientErrorAlarms(true)
+	alarms, _, err := GenerateAlarmsWithConfig(config, "./testdata/apigw.yml")
+	require.NoError(t, err)
+	clientErrorMetrics := make(map[string]string)
+	for _, alarm := range alarms {
+		if alarm.Properties.MetricName == "4XXError" || alarm.Properties.MetricName == "4xx" {
+			clientErrorMetrics[alarm.Properties.AlarmName] = alarm.Properties.MetricName
+		}
+	}
+	require.Equal(t, map[string]string{
+		"PantherAlarm-RestApiClientError-test-rest-api-RestApi-4XXError-Sum": "4XXError",
+		"PantherAlarm-HttpApiClientError-test-http-api-HttpApi-4xx-Sum":      "4xx",
+	}, clientErrorMetrics)
+}
+
+func TestGenerateAlarmsSimilarNames(t *testing.T) {
+	alarms, cf, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/similar.yml")
+	require.NoError(t, err)
+	var alarmNames []string
+	for _, alarm := range alarms {
+		alarmNames = append(alarmNames, alarm.Properties.AlarmName)
+	}
+	require.Equal(t, []string{
+		"PantherAlarm-SQSTooOld-test-events-EventsQueue-ApproximateAgeOfOldestMessage-Maximum",
+		"PantherAlarm-SQSTooOld-test_events-EventsQueueCopy-ApproximateAgeOfOldestMessage-Maximum",
+	}, alarmNames)
+
+	// same input, same output
+	for i := 0; i < 10; i++ {
+		repeatAlarms, repeatCf, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/similar.yml")
+		require.NoError(t, err)
+		require.Equal(t, alarms, repeatAlarms)
+		require.Equal(t, cf, repeatCf)
+	}
+}
+
+func TestSortAlarmsCollision(t *testing.T) {
+	// the names differ only in characters that are not allowed in CF resource names
+	alarms := []*Alarm{
+		NewAlarm("test-events", "PantherAlarm-SQSTooOld-test-events", "too old", "my-sns-topic-arn"),
+		NewAlarm("test_events", "PantherAlarm-SQSTooOld-test_events", "too old", "my-sns-topic-arn"),
+	}
+	err := sortAlarms(alarms)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "PantherAlarm-SQSTooOld-test_events () collides with PantherAlarm-SQSTooOld-test-events")
+
+	// the names differ only in digits, which are allowed
+	alarms = []*Alarm{
+		NewAlarm("q-1", "PantherAlarm-SQSTooOld-q-1-Queue1-ApproximateAgeOfOldestMessage-Maximum", "too old", "my-sns-topic-arn"),
+		NewAlarm("q-0", "PantherAlarm-SQSTooOld-q-0-Queue0-ApproximateAgeOfOldestMessage-Maximum", "too old", "my-sns-topic-arn"),
+		NewAlarm("test-lambda", "PantherAlarm-LambdaErrors-test-lambda-Function2-Errors-Sum", "errors", "my-sns-topic-arn"),
+		NewAlarm("test-lambda", "PantherAlarm-LambdaErrors-test-lambda-Function1-Errors-Sum", "errors", "my-sns-topic-arn"),
+	}
+	require.NoError(t, sortAlarms(alarms))
+	require.Equal(t, "PantherAlarmLambdaErrorstestlambdaFunction1ErrorsSum", cfResourceName(alarms[0].Properties.AlarmName))
+	require.Equal(t, "PantherAlarmSQSTooOldq0Queue0ApproximateAgeOfOldestMessageMaximum",
+		cfResourceName(alarms[2].Properties.AlarmName))
+}
+
+func TestAlarmLogicalIDsStable(t *testing.T) {
+	logicalIDs := func(templatePath string) map[string]string { // by alarm name
+		template, err := BuildAlarms(NewConfig("my-sns-topic-arn", testStackOutputs()), templatePath)
+		require.NoError(t, err)
+		cf, err := template.Marshal(JSONFormat)
+		require.NoError(t, err)
+		var parsed struct {
+			Resources map[string]struct{ Properties struct{ AlarmName string } }
+		}
+		require.NoError(t, json.Unmarshal(cf, &parsed))
+		ids := make(map[string]string, len(parsed.Resources))
+		for logicalID, resource := range parsed.Resources {
+			ids[resource.Properties.AlarmName] = logicalID
+		}
+		return ids
+	}
+
+	dir, err := ioutil.TempDir("", "cloudwatchcf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	baseline, err := ioutil.ReadFile("./testdata/cf.yml")
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "baseline.yml"), baseline, 0644))
+	baselineIDs := logicalIDs(filepath.Join(dir, "baseline.yml"))
+	require.NotEmpty(t, baselineIDs)
+
+	for name, resource := range map[string]string{
+		// the added resource is found first, so the order of all the others shifts
+		"first": `
+  AAddedQueue:
+    Type: AWS::SQS::Queue
+    Properties:
+      QueueName: added-queue
+`,
+		// the added resource is named like the Queue (test-sqs) but for a digit
+		"digit": `
+  Queue1:
+    Type: AWS::SQS::Queue
+    Properties:
+      QueueName: test-sqs1
+`,
+	} {
+		templatePath := filepath.Join(dir, name+".yml")
+		require.NoError(t, ioutil.WriteFile(templatePath, append(baseline, []byte(resource)...), 0644))
+		addedIDs := logicalIDs(templatePath)
+		require.Greater(t, len(addedIDs), len(baselineIDs), name)
+		for alarmName, logicalID := range baselineIDs {
+			require.Equal(t, logicalID, addedIDs[alarmName], name+": "+alarmName)
+		}
+	}
+}
+
+func TestAlarmResourceNameTooLong(t *testing.T) {
+	name := "PantherAlarm-LambdaErrors-" + strings.Repeat("very-long-function-name-", 20)
+	resourceName := cfResourceName(name + "Function-Errors-Sum")
+	require.Len(t, resourceName, maxResourceNameLength)
+	require.Equal(t, resourceName, cfResourceName(name+"Function-Errors-Sum"))
+	require.NotEqual(t, resourceName, cfResourceName(name+"Function-Throttles-Sum"))
+	require.Equal(t, "PantherAlarmLambdaErrorstestlambda", cfResourceName("PantherAlarm-LambdaErrors-test-lambda"))
+}
+
+func TestAlarmTreatMissingData(t *testing.T) {
+	dimensions := []MetricDimension{{Name: "FunctionName", Value: "test-lambda"}}
+
+	// error counts do not alarm without traffic
+	errorAlarm := NewAlarm("test-lambda", "PantherAlarm-LambdaErrors-test-lambda", "Lambda test-lambda is failing",
+		"my-sns-topic-arn").Metric("AWS/Lambda", "Errors", dimensions).SumCountThreshold(0, 60*5)
+	require.Equal(t, TreatMissingDataNotBreaching, errorAlarm.Properties.TreatMissingData)
+	alarmJSON, err := json.Marshal(errorAlarm)
+	require.NoError(t, err)
+	require.Contains(t, string(alarmJSON), `"TreatMissingData":"notBreaching"`)
+
+	// the CloudWatch default is not emitted
+	errorAlarm.TreatMissingData(TreatMissingDataMissing)
+	alarmJSON, err = json.Marshal(errorAlarm)
+	require.NoError(t, err)
+	require.NotContains(t, string(alarmJSON), "TreatMissingData")
+
+	// overridden per alarm
+	breaching := TreatMissingDataBreaching
+	errorAlarm.LogicalID = "Function"
+	Overrides{"Function": {"Errors": {TreatMissingData: &breaching}}}.apply(errorAlarm)
+	require.Equal(t, TreatMissingDataBreaching, errorAlarm.Properties.TreatMissingData)
+
+	errorAlarm.TreatMissingData("sometimes")
+	err = ValidateAlarms([]*Alarm{errorAlarm}, "./testdata/cf.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "treat missing data sometimes is not one of")
+}
+
+func TestGenerateSNSAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/sns.yml",
+		"./testdata/generated_test_sns_alarms.json").Alarms
+
+	metricsByTopic := make(map[string][]string)
+	for _, alarm := range alarms {
+		metricsByTopic[alarm.LogicalID] = append(metricsByTopic[alarm.LogicalID], alarm.Properties.MetricName)
+	}
+	failureMetrics := []string{"NumberOfNotificationsFailed", "NumberOfNotificationsFilteredOut-InvalidAttributes"}
+	require.Equal(t, map[string][]string{
+		"AlertsTopic":        failureMetrics,
+		"NotificationsTopic": failureMetrics,
+	}, metricsByTopic)
+}
+
+func TestGenerateECSServiceAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", testStackOutputs()), "./testdata/ecs.yml",
+		"./testdata/generated_test_ecs_alarms.json").Alarms
+
+	metricsByService := make(map[string][]string)
+	for _, alarm := range alarms {
+		metricsByService[alarm.LogicalID] = append(metricsByService[alarm.LogicalID], alarm.Properties.MetricName)
+	}
+	require.Equal(t, map[string][]string{
+		"Service":       {"CPUUtilization", "MemoryUtilization", "UnHealthyHostCount"},
+		"WorkerService": {"CPUUtilization", "MemoryUtilization"},
+	}, map[string][]string{
+		"Service":       metricsByService["Service"],
+		"WorkerService": metricsByService["WorkerService"],
+	})
+}
+
+func TestGenerateTargetGroupAlarms(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", testStackOutputs()).Exclude(&Exclusions{LogicalIDs: []string{"LoadBalancer"}})
+	template := requireGoldenAlarms(t, config, "./testdata/alb.yml", "./testdata/generated_test_alb_alarms.json")
+
+	metricsByTargetGroup := make(map[string][]string)
+	loadBalancerByTargetGroup := make(map[string]interface{})
+	for _, alarm := range template.Alarms {
+		require.Equal(t, "AWS/ApplicationELB", alarm.Properties.Namespace)
+		require.Equal(t, MetricDimension{Name: "TargetGroup", Value: map[string]interface{}{
+			"Fn::GetAtt": []interface{}{alarm.LogicalID, "TargetGroupFullName"}}}, alarm.Properties.Dimensions[0])
+		metricsByTargetGroup[alarm.LogicalID] = append(metricsByTargetGroup[alarm.LogicalID], alarm.Properties.MetricName)
+		loadBalancerByTargetGroup[alarm.LogicalID] = alarm.Properties.Dimensions[1].Value
+	}
+	require.Equal(t, map[string][]string{
+		"ApiTargetGroup":    {"HTTPCode_Target_5XX_Count", "TargetResponseTime", "UnHealthyHostCount"},
+		"UnusedTargetGroup": {"HTTPCode_Target_5XX_Count", "TargetResponseTime", "UnHealthyHostCount"},
+		"WebTargetGroup":    {"HTTPCode_Target_5XX_Count", "TargetResponseTime", "UnHealthyHostCount"},
+	}, metricsByTargetGroup)
+	// a listener in the template wins, otherwise the load balancer comes from the stack outputs
+	require.Equal(t, map[string]interface{}{
+		"ApiTargetGroup":    map[string]interface{}{"Fn::GetAtt": []interface{}{"LoadBalancer", "LoadBalancerFullName"}},
+		"UnusedTargetGroup": "testLoadbalancer",
+		"WebTargetGroup":    map[string]interface{}{"Fn::GetAtt": []interface{}{"LoadBalancer", "LoadBalancerFullName"}},
+	}, loadBalancerByTargetGroup)
+	require.Empty(t, template.Warnings)
+
+	// without the stack output the target group nothing forwards to cannot be alarmed
+	template, err := BuildAlarms(NewConfig("my-sns-topic-arn", nil).Exclude(&Exclusions{LogicalIDs: []string{"LoadBalancer"}}),
+		"./testdata/alb.yml")
+	require.NoError(t, err)
+	require.Len(t, template.Alarms, 6)
+	require.Equal(t, []Warning{{File: "./testdata/alb.yml", LogicalID: "UnusedTargetGroup", Type: targetGroupType,
+		Reason: "no load balancer in the template or the stack outputs forwards to the target group"}}, template.Warnings)
+}
+
+func TestGenerateCognitoAlarms(t *testing.T) {
+	template := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/cognito.yml",
+		"./testdata/generated_test_cognito_alarms.json")
+	require.Empty(t, template.Warnings)
+
+	poolByClient := make(map[string]interface{})
+	resourceByClient := make(map[string]string)
+	for _, alarm := range template.Alarms {
+		require.Equal(t, "AWS/Cognito", alarm.Properties.Namespace)
+		resourceByClient[alarm.LogicalID] = alarm.Resource
+		require.Equal(t, MetricDimension{Name: "UserPoolClient", Value: cfngen.Ref{Ref: alarm.LogicalID}},
+			alarm.Properties.Dimensions[1])
+		poolByClient[alarm.LogicalID] = alarm.Properties.Dimensions[0]
+	}
+	require.Equal(t, map[string]interface{}{
+		"AppClient":      MetricDimension{Name: "UserPool", Value: cfngen.Ref{Ref: "UserPool"}},
+		"ImportedClient": MetricDimension{Name: "UserPool", Value: "us-east-1_aBcDeFgHi"},
+	}, poolByClient)
+	// the alarms are documented by the pool, the client is only in the alarm name
+	require.Equal(t, map[string]string{"AppClient": "panther-users", "ImportedClient": "us-east-1_aBcDeFgHi"}, resourceByClient)
+
+	// the sign-in success rate is tuned per client
+	threshold := float32(0.8)
+	overrides := Overrides{"AppClient": {"SignInSuccesses": {Threshold: &threshold}}}
+	alarms, _, err := GenerateAlarmsWithConfig(NewConfig("my-sns-topic-arn", nil).Overrides(overrides),
+		"./testdata/cognito.yml")
+	require.NoError(t, err)
+	for _, alarm := range alarms {
+		if alarm.Properties.MetricName != "SignInSuccesses" {
+			continue
+		}
+		expected := defaultCognitoSignInSuccessThreshold
+		if alarm.LogicalID == "AppClient" {
+			expected = threshold
+		}
+		require.Equal(t, expected, *alarm.Properties.Threshold, alarm.Properties.AlarmName)
+		require.Equal(t, "LessThanThreshold", alarm.Properties.ComparisonOperator, alarm.Properties.AlarmName)
+	}
+}
+
+func TestGenerateECSServiceAlarmsOverrideThreshold(t *testing.T) {
+	threshold := float32(95)
+	overrides := Overrides{"Service": {"CPUUtilization": {Threshold: &threshold}}}
+	config := NewConfig("my-sns-topic-arn", testStackOutputs()).Overrides(overrides)
+	alarms, _, err := GenerateAlarmsWithConfig(config, "./testdata/ecs.yml")
+	require.NoError(t, err)
+	for _, alarm := range alarms {
+		expected := defaultECSUtilizationThreshold
+		switch {
+		case alarm.Properties.Namespace != "AWS/ECS":
+			continue
+		case alarm.LogicalID == "Service" && alarm.Properties.MetricName == "CPUUtilization":
+			expected = threshold
+		}
+		require.Equal(t, expected, *alarm.Properties.Threshold, alarm.Properties.AlarmName)
+	}
+}
+
+func TestGenerateAlarmsDatapointsToAlarm(t *testing.T) {
+	evaluationPeriods, datapointsToAlarm := 5, 3
+	overrides := Overrides{"Service": {"CPUUtilization": {
+		EvaluationPeriods: &evaluationPeriods,
+		DatapointsToAlarm: &datapointsToAlarm,
+	}}}
+	config := NewConfig("my-sns-topic-arn", testStackOutputs()).Overrides(overrides)
+	alarms, _, err := GenerateAlarmsWithConfig(config, "./testdata/ecs.yml")
+	require.NoError(t, err)
+	require.NoError(t, ValidateAlarms(alarms, "./testdata/ecs.yml"))
+
+	var mOfN int
+	for _, alarm := range alarms {
+		alarmJSON, err := json.Marshal(alarm)
+		require.NoError(t, err)
+		if alarm.LogicalID == "Service" && alarm.Properties.MetricName == "CPUUtilization" {
+			mOfN++
+			require.Contains(t, string(alarmJSON), `"DatapointsToAlarm":3`)
+			require.Contains(t, string(alarmJSON), `"EvaluationPeriods":5`)
+			continue
+		}
+		require.NotContains(t, string(alarmJSON), "DatapointsToAlarm", alarm.Properties.AlarmName)
+	}
+	require.Equal(t, 1, mOfN)
+
+	// same as the evaluation periods is the default and not rendered
+	datapointsToAlarm = evaluationPeriods
+	alarms, _, err = GenerateAlarmsWithConfig(config, "./testdata/ecs.yml")
+	require.NoError(t, err)
+	for _, alarm := range alarms {
+		require.Zero(t, alarm.Properties.DatapointsToAlarm, alarm.Properties.AlarmName)
+	}
+
+	// more datapoints than evaluation periods can never alarm
+	datapointsToAlarm = evaluationPeriods + 1
+	_, _, err = GenerateAlarmsWithConfig(config, "./testdata/ecs.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "datapoints to alarm 6 exceeds evaluation periods 5")
+}
+
+func TestGenerateCloudFrontAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/cloudfront.yml",
+		"./testdata/generated_test_cloudfront_alarms.json").Alarms
+
+	require.Len(t, alarms, 2)
+	for _, alarm := range alarms {
+		require.Equal(t, "AWS/CloudFront", alarm.Properties.Namespace)
+		require.Equal(t, []MetricDimension{
+			{Name: "DistributionId", Value: cfngen.Ref{Ref: "WebDistribution"}},
+			{Name: "Region", Value: "Global"},
+		}, alarm.Properties.Dimensions)
+	}
+}
+
+func TestGenerateGlueJobAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/glue.yml",
+		"./testdata/generated_test_glue_alarms.json").Alarms
+
+	// only the tagged job is alarmed
+	require.Len(t, alarms, 2)
+	for _, alarm := range alarms {
+		require.Equal(t, "CompactionJob", alarm.LogicalID)
+		require.Equal(t, "Glue", alarm.Properties.Namespace)
+		require.Equal(t, []MetricDimension{
+			{Name: "JobName", Value: "panther-compaction"},
+			{Name: "JobRunId", Value: "ALL"},
+			{Name: "Type", Value: "count"},
+		}, alarm.Properties.Dimensions[:3])
+	}
+}
+
+func TestGenerateAlarmsDescriptionTemplate(t *testing.T) {
+	descriptionTemplate, err := ParseAlarmDescriptionTemplate(
+		"{{.ResourceType}} {{.LogicalID}} {{.MetricName}} exceeded {{.Threshold}} " +
+			"(owner {{index .Tags \"owner\"}}) - see runbook {{.RunbookURL}}")
+	require.NoError(t, err)
+	config := NewConfig("my-sns-topic-arn", nil).AlarmDescriptionTemplate(descriptionTemplate)
+	alarms, _, err := GenerateAlarmsWithConfig(config, "./testdata/glue.yml")
+	require.NoError(t, err)
+	require.Len(t, alarms, 2)
+	require.Equal(t, "AWS::Glue::Job CompactionJob glue.error.ALL exceeded 0 "+
+		"(owner data-lake) - see runbook https://docs.runpanther.io/operations/runbooks#panther-compaction",
+		alarms[0].Properties.AlarmDescription)
+
+	// the default template keeps the description of the alarm type
+	descriptionTemplate, err = ParseAlarmDescriptionTemplate(DefaultAlarmDescriptionTemplate)
+	require.NoError(t, err)
+	_, cf, err := GenerateAlarmsWithConfig(config.AlarmDescriptionTemplate(descriptionTemplate), "./testdata/glue.yml")
+	require.NoError(t, err)
+	expectedCf, err := readTestFile("./testdata/generated_test_glue_alarms.json")
 	require.NoError(t, err)
 	require.Equal(t, expectedCf, cf)
+
+	_, err = ParseAlarmDescriptionTemplate("{{.Unclosed")
+	require.Error(t, err)
+}
+
+func TestGenerateLambdaFunctionAlarms(t *testing.T) {
+	alarms, _, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/lambda.yml")
+	require.NoError(t, err)
+	require.NoError(t, ValidateAlarms(alarms, "./testdata/lambda.yml"))
+
+	alarmsByFunction := make(map[string][]*Alarm)
+	for _, alarm := range alarms {
+		alarmsByFunction[alarm.LogicalID] = append(alarmsByFunction[alarm.LogicalID], alarm)
+	}
+	require.Len(t, alarmsByFunction["SamFunction"], 7)
+
+	// a raw Lambda function has the same alarms as the SAM function it would expand from
+	samAlarms, err := json.Marshal(alarmsByFunction["SamFunction"])
+	require.NoError(t, err)
+	rawAlarms, err := json.Marshal(alarmsByFunction["RawFunction"])
+	require.NoError(t, err)
+	samAsRaw := strings.NewReplacer("sam-function", "raw-function", "SamFunction", "RawFunction").Replace(string(samAlarms))
+	require.Equal(t, samAsRaw, string(rawAlarms))
+}
+
+func TestGenerateAppSyncAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/appsync.yml",
+		"./testdata/generated_test_appsync_alarms.json").Alarms
+
+	require.Len(t, alarms, 3)
+	for _, alarm := range alarms {
+		require.Equal(t, []MetricDimension{{Name: "GraphQLAPIId",
+			Value: map[string]interface{}{"Fn::GetAtt": []interface{}{"GraphQLApi", "ApiId"}}}}, alarm.Properties.Dimensions)
+		if alarm.Properties.MetricName == "Latency" {
+			require.Equal(t, "p90", alarm.Properties.ExtendedStatistic)
+			require.Empty(t, alarm.Properties.Statistic)
+		}
+	}
+}
+
+func TestGenerateRDSAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/rds.yml",
+		"./testdata/generated_test_rds_alarms.json").Alarms
+
+	// free resources alarm when below the threshold
+	comparisons := make(map[string]string)
+	for _, alarm := range alarms {
+		comparisons[alarm.LogicalID+"."+alarm.Properties.MetricName] = alarm.Properties.ComparisonOperator
+	}
+	require.Equal(t, map[string]string{
+		"MetadataDatabase.CPUUtilization":      "GreaterThanThreshold",
+		"MetadataDatabase.DatabaseConnections": "GreaterThanThreshold",
+		"MetadataDatabase.FreeableMemory":      "LessThanThreshold",
+		"MetadataDatabase.FreeStorageSpace":    "LessThanThreshold",
+		"AnalyticsCluster.CPUUtilization":      "GreaterThanThreshold",
+		"AnalyticsCluster.DatabaseConnections": "GreaterThanThreshold",
+		"AnalyticsCluster.FreeableMemory":      "LessThanThreshold",
+		"AnalyticsCluster.FreeLocalStorage":    "LessThanThreshold",
+	}, comparisons)
+}
+
+func TestGenerateFirehoseAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/firehose.yml",
+		"./testdata/generated_test_firehose_alarms.json").Alarms
+
+	comparisons := make(map[string]string)
+	for _, alarm := range alarms {
+		comparisons[alarm.Properties.MetricName] = alarm.Properties.ComparisonOperator
+	}
+	require.Equal(t, map[string]string{
+		"DeliveryToS3.DataFreshness": "GreaterThanThreshold",
+		"DeliveryToS3.Success":       "LessThanThreshold", // the ratio of successful deliveries drops
+		"ThrottledRecords":           "GreaterThanThreshold",
+	}, comparisons)
+
+	// tolerate a fraction of failed deliveries
+	threshold := float32(0.95)
+	config := NewConfig("my-sns-topic-arn", nil).
+		Overrides(Overrides{"LogDeliveryStream": {"DeliveryToS3.Success": {Threshold: &threshold}}})
+	_, cf, err := GenerateAlarmsWithConfig(config, "./testdata/firehose.yml")
+	require.NoError(t, err)
+	require.Contains(t, string(cf), `"Threshold": 0.95,`)
+}
+
+func TestGenerateEventSourceMappingAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/eventsource.yml",
+		"./testdata/generated_test_eventsource_alarms.json").Alarms
+
+	lagAlarms := make(map[string]*Alarm) // by logical id of the mapping
+	for _, alarm := range alarms {
+		// the consumed queue alarms with its mapping, not twice
+		require.False(t, strings.HasPrefix(alarm.Properties.AlarmName, "PantherAlarm-SQSTooOld-test-consumed-queue"))
+		if strings.HasSuffix(alarm.LogicalID, "Mapping") {
+			lagAlarms[alarm.LogicalID] = alarm
+		}
+	}
+	require.Len(t, lagAlarms, 3) // not for the DynamoDB stream
+
+	queueAlarm := lagAlarms["QueueMapping"]
+	require.Equal(t, "ApproximateAgeOfOldestMessage", queueAlarm.Properties.MetricName)
+	require.Equal(t, []MetricDimension{{Name: "QueueName", Value: "test-consumed-queue"}}, queueAlarm.Properties.Dimensions)
+	require.Contains(t, queueAlarm.Properties.AlarmDescription, "by Lambda test-consumer")
+
+	streamAlarm := lagAlarms["StreamMapping"]
+	require.Equal(t, "IteratorAge", streamAlarm.Properties.MetricName)
+	require.Equal(t, []MetricDimension{{Name: "FunctionName", Value: "test-consumer"}}, streamAlarm.Properties.Dimensions)
+	require.Contains(t, streamAlarm.Properties.AlarmDescription, "Lambda test-consumer is not reading Kinesis stream test-consumed-stream")
+
+	otherAlarm := lagAlarms["OtherQueueMapping"]
+	require.Equal(t, []MetricDimension{{Name: "QueueName", Value: "test-other-queue"}}, otherAlarm.Properties.Dimensions)
+	require.Contains(t, otherAlarm.Properties.AlarmDescription, "by Lambda test-other-consumer")
+}
+
+func TestGenerateElasticsearchAlarms(t *testing.T) {
+	alarms := requireGoldenAlarms(t, NewConfig("my-sns-topic-arn", nil), "./testdata/elasticsearch.yml",
+		"./testdata/generated_test_elasticsearch_alarms.json").Alarms
+
+	thresholds := make(map[string]float32) // by logical id and metric
+	for _, alarm := range alarms {
+		require.Equal(t, "AWS/ES", alarm.Properties.Namespace)
+		require.Equal(t, MetricDimension{Name: "ClientId", Value: cfngen.Ref{Ref: "AWS::AccountId"}},
+			alarm.Properties.Dimensions[1])
+		thresholds[alarm.LogicalID+"/"+alarm.Properties.MetricName] = *alarm.Properties.Threshold
+		if alarm.Properties.MetricName == "ClusterStatus.red" {
+			require.Equal(t, "GreaterThanOrEqualToThreshold", alarm.Properties.ComparisonOperator)
+		}
+	}
+	require.Len(t, thresholds, 8) // both domains
+	require.Equal(t, float32(25*1024), thresholds["SearchDomain/FreeStorageSpace"])
+	require.Equal(t, defaultElasticsearchFreeStorageThreshold, thresholds["OpenSearchDomain/FreeStorageSpace"])
+}
+
+func TestGenerateEventsRuleAlarms(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", nil).Exclude(&Exclusions{LogicalIDs: []string{"AlertsQueue", "Function"}})
+	alarms := requireGoldenAlarms(t, config, "./testdata/events.yml", "./testdata/generated_test_events_alarms.json").Alarms
+
+	dimensions := make(map[string][]MetricDimension) // by logical id
+	for _, alarm := range alarms {
+		require.Equal(t, "AWS/Events", alarm.Properties.Namespace)
+		dimensions[alarm.LogicalID] = alarm.Properties.Dimensions
+	}
+	require.Len(t, alarms, 6)
+	require.NotContains(t, dimensions, "UnusedRule") // no targets
+	require.Equal(t, []MetricDimension{{Name: "RuleName", Value: "panther-alerts"}}, dimensions["AlertsRule"])
+	require.Equal(t, []MetricDimension{{Name: "RuleName", Value: cfngen.Ref{Ref: "ScheduleRule"}}},
+		dimensions["ScheduleRule"])
+	require.Equal(t, []MetricDimension{
+		{Name: "EventBusName", Value: map[string]interface{}{"Ref": "EventBus"}},
+		{Name: "RuleName", Value: map[string]interface{}{"Fn::Select": []interface{}{1,
+			map[string]interface{}{"Fn::Split": []interface{}{"|", cfngen.Ref{Ref: "BusRule"}}}}}},
+	}, dimensions["BusRule"])
+}
+
+func TestGenerateLambdaConcurrencyAlarms(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", nil).LambdaConcurrencyLimit(1000)
+	alarms := requireGoldenAlarms(t, config, "./testdata/lambda_concurrency.yml",
+		"./testdata/generated_test_lambda_concurrency_alarms.json").Alarms
+
+	byMetric := make(map[string]*Alarm) // by logical id and metric name
+	for _, alarm := range alarms {
+		byMetric[alarm.LogicalID+"/"+alarm.Properties.MetricName] = alarm
+	}
+	require.NotContains(t, byMetric, "TestAlias/ProvisionedConcurrencyUtilization") // no provisioned concurrency
+
+	account := byMetric["/ConcurrentExecutions"]
+	require.NotNil(t, account)
+	require.Equal(t, "PantherAlarm-LambdaAccountConcurrency-Lambda-ConcurrentExecutions-Maximum", account.Properties.AlarmName)
+	require.Empty(t, account.Properties.Dimensions)
+	require.Equal(t, float32(800), *account.Properties.Threshold)
+
+	reserved := byMetric["Function/ConcurrentExecutions"]
+	require.NotNil(t, reserved)
+	require.Equal(t, float32(40), *reserved.Properties.Threshold)
+	require.Equal(t, []MetricDimension{{Name: "FunctionName", Value: cfngen.Ref{Ref: "Function"}}},
+		reserved.Properties.Dimensions)
+
+	utilization := byMetric["LiveAlias/ProvisionedConcurrencyUtilization"]
+	require.NotNil(t, utilization)
+	require.Equal(t, "PantherAlarm-LambdaProvisionedConcurrency-Function:live-LiveAlias-ProvisionedConcurrencyUtilization-Maximum",
+		utilization.Properties.AlarmName)
+	require.Equal(t, []MetricDimension{
+		{Name: "FunctionName", Value: cfngen.Ref{Ref: "Function"}},
+		{Name: "Resource", Value: map[string]interface{}{"Fn::Join": []interface{}{"",
+			[]interface{}{cfngen.Ref{Ref: "Function"}, ":", "live"}}}},
+	}, utilization.Properties.Dimensions)
+	require.Contains(t, byMetric, "LiveAlias/ProvisionedConcurrencySpilloverInvocations")
+
+	spillover := byMetric["ApiFunction/ProvisionedConcurrencySpilloverInvocations"]
+	require.NotNil(t, spillover)
+	require.Equal(t, []MetricDimension{
+		{Name: "FunctionName", Value: "panther-api"},
+		{Name: "Resource", Value: "panther-api:live"},
+	}, spillover.Properties.Dimensions)
+	require.Contains(t, byMetric, "ApiFunction/ProvisionedConcurrencyUtilization")
 }
@@ -0,0 +1,335 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+// knownMetrics are the metrics alarms may be configured on, by resource type then namespace
+var knownMetrics = map[string]map[string][]string{
+	"AWS::CloudFront::Distribution": {
+		"AWS/CloudFront": {"5xxErrorRate", "TotalErrorRate"},
+	},
+	"AWS::Glue::Job": {
+		"Glue": {"glue.driver.aggregate.numFailedTasks", "glue.error.ALL"},
+	},
+	elasticsearchDomainType: {
+		"AWS/ES": {"ClusterStatus.red", "FreeStorageSpace", "JVMMemoryPressure", "CPUUtilization"},
+	},
+	openSearchDomainType: {
+		"AWS/ES": {"ClusterStatus.red", "FreeStorageSpace", "JVMMemoryPressure", "CPUUtilization"},
+	},
+	"AWS::RDS::DBInstance": {
+		"AWS/RDS": {"CPUUtilization", "FreeStorageSpace", "DatabaseConnections", "FreeableMemory"},
+	},
+	"AWS::RDS::DBCluster": {
+		"AWS/RDS": {"CPUUtilization", "FreeLocalStorage", "DatabaseConnections", "FreeableMemory"},
+	},
+	"AWS::KinesisFirehose::DeliveryStream": {
+		"AWS/Firehose": {"DeliveryToS3.DataFreshness", "DeliveryToS3.Success", "ThrottledRecords"},
+	},
+	eventsRuleType: {
+		"AWS/Events": {"FailedInvocations", "ThrottledRules"},
+	},
+	"AWS::SNS::Topic": {
+		"AWS/SNS": {"NumberOfNotificationsFailed", "NumberOfNotificationsFilteredOut-InvalidAttributes"},
+	},
+	"AWS::SQS::Queue": {
+		"AWS/SQS": {"ApproximateAgeOfOldestMessage", "ApproximateNumberOfMessagesVisible"},
+	},
+	eventSourceMappingType: {
+		"AWS/SQS":    {"ApproximateAgeOfOldestMessage"},
+		"AWS/Lambda": {"IteratorAge"},
+	},
+	"AWS::Serverless::Api": {
+		"AWS/ApiGateway": {"5XXError", "Latency", "IntegrationLatency"},
+	},
+	restAPIType: {
+		"AWS/ApiGateway": {"5XXError", "4XXError", "Latency", "Count"},
+	},
+	httpAPIType: {
+		"AWS/ApiGateway": {"5xx", "4xx", "Latency", "Count"},
+	},
+	"AWS::ElasticLoadBalancingV2::LoadBalancer": {
+		"AWS/ApplicationELB": {"HTTPCode_Target_4XX_Count", "HTTPCode_ELB_4XX_Count", "TargetResponseLatency",
+			"UnHealthyHostCount"},
+	},
+	targetGroupType: {
+		"AWS/ApplicationELB": {"UnHealthyHostCount", "HTTPCode_Target_5XX_Count", "TargetResponseTime"},
+	},
+	"AWS::AppSync::GraphQLApi": {
+		"AWS/AppSync": {"5XXError", "4XXError", "Latency"},
+	},
+	cognitoUserPoolClientType: {
+		"AWS/Cognito": {"SignInSuccesses", "SignInThrottles", "TokenRefreshThrottles"},
+	},
+	"AWS::DynamoDB::Table": {
+		"AWS/DynamoDB": {"SystemErrors", "ThrottledRequests", "SuccessfulRequestLatency", "ReadThrottleEvents",
+			"WriteThrottleEvents", "ConsumedReadCapacityUnits", "ConsumedWriteCapacityUnits"},
+	},
+	"AWS::Serverless::Function": {
+		"AWS/Lambda": {"Errors", "Throttles", "Duration", "Invocations", "ConcurrentExecutions",
+			"ProvisionedConcurrencyUtilization", "ProvisionedConcurrencySpilloverInvocations"},
+	},
+	"AWS::Lambda::Function": {
+		"AWS/Lambda": {"Errors", "Throttles", "Duration", "Invocations", "ConcurrentExecutions",
+			"ProvisionedConcurrencyUtilization", "ProvisionedConcurrencySpilloverInvocations"},
+	},
+	lambdaAliasType: {
+		"AWS/Lambda": {"ProvisionedConcurrencyUtilization", "ProvisionedConcurrencySpilloverInvocations"},
+	},
+	"AWS::Kinesis::Stream": {
+		"AWS/Kinesis": {"GetRecords.IteratorAgeMilliseconds", "ReadProvisionedThroughputExceeded",
+			"WriteProvisionedThroughputExceeded"},
+	},
+	"AWS::ECS::Service": {
+		"AWS/ECS":            {"CPUUtilization", "MemoryUtilization"},
+		"AWS/ApplicationELB": {"UnHealthyHostCount"},
+	},
+	"AWS::StepFunctions::StateMachine": {
+		"AWS/States": {"ExecutionsFailed", "ExecutionsTimedOut", "ExecutionThrottled"},
+	},
+}
+
+// nameDimensions are the alarm dimensions holding a resource name, which must match a resource in the template
+var nameDimensions = map[string]string{
+	"FunctionName":         "FunctionName",
+	"QueueName":            "QueueName",
+	"TableName":            "TableName",
+	"TopicName":            "TopicName",
+	"StreamName":           "Name",
+	"Name":                 "Name", // ApiGateway
+	"ApiName":              "Name",
+	"JobName":              "Name", // Glue
+	"DBInstanceIdentifier": "DBInstanceIdentifier",
+	"DBClusterIdentifier":  "DBClusterIdentifier",
+	"DeliveryStreamName":   "DeliveryStreamName",
+	"DomainName":           "DomainName",
+	"RuleName":             "Name", // EventBridge
+}
+
+// alarmTemplate is what alarms are validated against, collected from the CF in the cfDirs
+type alarmTemplate struct {
+	resources     map[string]map[interface{}]interface{} // by logical id
+	parameters    map[string]struct{}                    // by logical id, dimensions may Ref these
+	resourceNames map[string]struct{}                    // literal names of resources (e.g., FunctionName)
+	metricFilters map[string]struct{}                    // names of metrics generated from log metric filters
+}
+
+// ValidateAlarms checks the alarms against the CF in the cfDirs they were generated from: referenced resources must
+// exist, metrics must be known for the resource type and settings must be within sane bounds.
+// All problems are reported in the returned error.
+func ValidateAlarms(alarms []*Alarm, cfDirs ...string) error {
+	template, err := readAlarmTemplate(cfDirs...)
+	if err != nil {
+		return err
+	}
+
+	var problems []string
+	for _, alarm := range alarms {
+		problems = append(problems, template.validate(alarm)...)
+	}
+	if len(problems) > 0 {
+		return errors.New("invalid alarms:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func readAlarmTemplate(cfDirs ...string) (template *alarmTemplate, err error) {
+	template = &alarmTemplate{
+		resources:     make(map[string]map[interface{}]interface{}),
+		parameters:    make(map[string]struct{}),
+		resourceNames: make(map[string]struct{}),
+		metricFilters: make(map[string]struct{}),
+	}
+	for _, cfDir := range cfDirs {
+		err = walkYamlFiles(cfDir, func(path string) error {
+			yamlObj, err := readYaml(path)
+			if err != nil {
+				return err
+			}
+			cf, _ := yamlObj.(map[interface{}]interface{})
+			if parameters, ok := cf["Parameters"].(map[interface{}]interface{}); ok {
+				for logicalID := range parameters {
+					template.parameters[fmt.Sprintf("%v", logicalID)] = struct{}{}
+				}
+			}
+			resources := getResources(yamlObj)
+			for logicalID, resource := range resources {
+				template.resources[logicalID] = resource
+				if name := getLiteralResourceName(resource); name != "" {
+					template.resourceNames[name] = struct{}{}
+				}
+				if resource["Type"] == eventSourceMappingType { // the source and function may be of another stack
+					_, sourceName, _ := eventSource(getResourceNestedProperty(resource, "EventSourceArn"), resources)
+					functionName, _ := eventSourceFunction(logicalID, getResourceNestedProperty(resource, "FunctionName"),
+						resources)
+					template.resourceNames[sourceName] = struct{}{}
+					template.resourceNames[functionName] = struct{}{}
+				}
+			}
+			// only the names are needed
+			for _, metricFilter := range resourceMetricFilters(resources, NewConfig("", nil)) {
+				for _, transformation := range metricFilter.Properties.MetricTransformations {
+					template.metricFilters[transformation.MetricName] = struct{}{}
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return template, nil
+}
+
+// validate returns the problems with the alarm, if any
+func (template *alarmTemplate) validate(alarm *Alarm) (problems []string) {
+	name := alarm.Properties.AlarmName
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, name+": "+fmt.Sprintf(format, args...))
+	}
+
+	var resourceType string
+	if alarm.LogicalID != "" {
+		resource, found := template.resources[alarm.LogicalID]
+		if !found {
+			problem("resource %s is not in the template", alarm.LogicalID)
+		} else {
+			resourceType, _ = resource["Type"].(string)
+		}
+	}
+
+	for _, metric := range alarmMetrics(alarm) {
+		if isMetricFilterNamespace(metric.Namespace) {
+			if _, found := template.metricFilters[metric.MetricName]; !found {
+				problem("metric %s/%s is not generated by a metric filter", metric.Namespace, metric.MetricName)
+			}
+		} else if metrics, found := knownMetrics[resourceType]; found {
+			if !containsString(metrics[metric.Namespace], metric.MetricName) {
+				problem("metric %s/%s is not known for %s", metric.Namespace, metric.MetricName, resourceType)
+			}
+		}
+
+		for _, dimension := range metric.Dimensions {
+			if logicalID := dimensionLogicalID(dimension.Value); logicalID != "" {
+				if !template.defines(logicalID) {
+					problem("dimension %s references resource %s which is not in the template", dimension.Name, logicalID)
+				}
+				continue
+			}
+			value, isLiteral := dimension.Value.(string)
+			if _, isName := nameDimensions[dimension.Name]; isName && isLiteral {
+				if _, found := template.resourceNames[value]; !found {
+					problem("dimension %s references %s which is not in the template", dimension.Name, value)
+				}
+			}
+		}
+	}
+
+	props := alarm.Properties
+	if props.Period < 0 {
+		problem("period %d is negative", props.Period)
+	} else if !validPeriod(props.Period) {
+		problem("period %d is not 10, 30 or a multiple of 60", props.Period)
+	}
+	for _, query := range props.Metrics {
+		if query.MetricStat != nil && query.MetricStat.Period < 0 {
+			problem("period %d of metric %s is negative", query.MetricStat.Period, query.ID)
+		} else if query.MetricStat != nil && !validPeriod(query.MetricStat.Period) {
+			problem("period %d of metric %s is not 10, 30 or a multiple of 60", query.MetricStat.Period, query.ID)
+		}
+	}
+	if props.EvaluationPeriods < 1 {
+		problem("evaluation periods %d is less than 1", props.EvaluationPeriods)
+	}
+	if props.DatapointsToAlarm < 0 || props.DatapointsToAlarm > props.EvaluationPeriods {
+		problem("datapoints to alarm %d is not between 1 and the evaluation periods %d",
+			props.DatapointsToAlarm, props.EvaluationPeriods)
+	}
+	switch props.TreatMissingData {
+	case "", TreatMissingDataNotBreaching, TreatMissingDataBreaching, TreatMissingDataIgnore, TreatMissingDataMissing:
+	default:
+		problem("treat missing data %s is not one of %s, %s, %s or %s", props.TreatMissingData,
+			TreatMissingDataNotBreaching, TreatMissingDataBreaching, TreatMissingDataIgnore, TreatMissingDataMissing)
+	}
+	if props.Statistic != "" && props.ExtendedStatistic != "" {
+		problem("statistic %s and extended statistic %s are exclusive", props.Statistic, props.ExtendedStatistic)
+	}
+	if props.Threshold != nil && *props.Threshold < 0 {
+		problem("threshold %g is negative", *props.Threshold)
+	}
+	return problems
+}
+
+// defines returns true if the logical id is a resource, parameter or pseudo parameter (e.g., AWS::Region)
+func (template *alarmTemplate) defines(logicalID string) bool {
+	if _, found := template.resources[logicalID]; found {
+		return true
+	}
+	_, found := template.parameters[logicalID]
+	return found || strings.HasPrefix(logicalID, "AWS::")
+}
+
+// alarmMetrics returns the metrics the alarm is configured on, either directly or as metric data queries
+func alarmMetrics(alarm *Alarm) (metrics []Metric) {
+	if alarm.Properties.MetricName != "" {
+		metrics = append(metrics, Metric{
+			Namespace:  alarm.Properties.Namespace,
+			MetricName: alarm.Properties.MetricName,
+			Dimensions: alarm.Properties.Dimensions,
+		})
+	}
+	for _, query := range alarm.Properties.Metrics {
+		if query.MetricStat != nil {
+			metrics = append(metrics, query.MetricStat.Metric)
+		}
+	}
+	return metrics
+}
+
+// dimensionLogicalID returns the logical id referenced by a Ref or Fn::GetAtt dimension value, or "" if none
+func dimensionLogicalID(value interface{}) string {
+	switch val := value.(type) {
+	case cfngen.Ref:
+		return val.Ref
+	case *cfngen.Ref:
+		return val.Ref
+	case map[string]interface{}:
+		if ref, ok := val["Ref"].(string); ok {
+			return ref
+		}
+		return getAttValueLogicalID(val["Fn::GetAtt"])
+	}
+	return ""
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
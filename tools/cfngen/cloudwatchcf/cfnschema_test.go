@@ -0,0 +1,356 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+// A focused subset of the CloudFormation resource specification for the resources cloudwatchcf emits, so the tests
+// catch output CloudFormation rejects (the golden files only catch output that changed).
+// see: https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/cfn-resource-specification.html
+
+type cfnKind int
+
+const (
+	cfnString cfnKind = iota
+	cfnInteger
+	cfnNumber
+	cfnBoolean
+	cfnList // of strings, or of objects with the properties
+	cfnObject
+)
+
+type cfnProperty struct {
+	kind       cfnKind
+	required   bool
+	enum       []string      // allowed values of a string, nil for any
+	properties cfnProperties // of an object, or of the objects in a list
+}
+
+type cfnProperties map[string]cfnProperty
+
+var (
+	cfnUnits = []string{"Seconds", "Microseconds", "Milliseconds", "Bytes", "Kilobytes", "Megabytes", "Gigabytes",
+		"Terabytes", "Bits", "Kilobits", "Megabits", "Gigabits", "Terabits", "Percent", "Count", "Bytes/Second",
+		"Kilobytes/Second", "Megabytes/Second", "Gigabytes/Second", "Terabytes/Second", "Bits/Second",
+		"Kilobits/Second", "Megabits/Second", "Gigabits/Second", "Terabits/Second", "Count/Second", "None"}
+
+	cfnDimensionProperties = cfnProperties{
+		"Name":  {kind: cfnString, required: true},
+		"Value": {kind: cfnString, required: true},
+	}
+
+	cfnTagProperties = cfnProperties{
+		"Key":   {kind: cfnString, required: true},
+		"Value": {kind: cfnString, required: true},
+	}
+
+	cfnMetricDataQueryProperties = cfnProperties{
+		"Id":         {kind: cfnString, required: true},
+		"Expression": {kind: cfnString},
+		"Label":      {kind: cfnString},
+		"Period":     {kind: cfnInteger},
+		"ReturnData": {kind: cfnBoolean},
+		"MetricStat": {kind: cfnObject, properties: cfnProperties{
+			"Metric": {kind: cfnObject, required: true, properties: cfnProperties{
+				"Namespace":  {kind: cfnString},
+				"MetricName": {kind: cfnString},
+				"Dimensions": {kind: cfnList, properties: cfnDimensionProperties},
+			}},
+			"Period": {kind: cfnInteger, required: true},
+			"Stat":   {kind: cfnString, required: true},
+			"Unit":   {kind: cfnString, enum: cfnUnits},
+		}},
+	}
+
+	cfnResourceProperties = map[string]cfnProperties{
+		"AWS::CloudWatch::Alarm": {
+			"ActionsEnabled":   {kind: cfnBoolean},
+			"AlarmActions":     {kind: cfnList},
+			"AlarmDescription": {kind: cfnString},
+			"AlarmName":        {kind: cfnString},
+			"ComparisonOperator": {kind: cfnString, required: true, enum: []string{
+				"GreaterThanOrEqualToThreshold", "GreaterThanThreshold", "LessThanThreshold",
+				"LessThanOrEqualToThreshold", "LessThanLowerOrGreaterThanUpperThreshold", "LessThanLowerThreshold",
+				"GreaterThanUpperThreshold"}},
+			"DatapointsToAlarm":                {kind: cfnInteger},
+			"Dimensions":                       {kind: cfnList, properties: cfnDimensionProperties},
+			"EvaluateLowSampleCountPercentile": {kind: cfnString, enum: []string{"evaluate", "ignore"}},
+			"EvaluationPeriods":                {kind: cfnInteger, required: true},
+			"ExtendedStatistic":                {kind: cfnString},
+			"InsufficientDataActions":          {kind: cfnList},
+			"MetricName":                       {kind: cfnString},
+			"Metrics":                          {kind: cfnList, properties: cfnMetricDataQueryProperties},
+			"Namespace":                        {kind: cfnString},
+			"OKActions":                        {kind: cfnList},
+			"Period":                           {kind: cfnInteger},
+			"Statistic": {kind: cfnString, enum: []string{
+				"SampleCount", "Average", "Sum", "Minimum", "Maximum"}},
+			"Tags":              {kind: cfnList, properties: cfnTagProperties},
+			"Threshold":         {kind: cfnNumber},
+			"ThresholdMetricId": {kind: cfnString},
+			"TreatMissingData": {kind: cfnString, enum: []string{
+				"breaching", "notBreaching", "ignore", "missing"}},
+			"Unit": {kind: cfnString, enum: cfnUnits},
+		},
+		"AWS::CloudWatch::CompositeAlarm": {
+			"ActionsEnabled":                   {kind: cfnBoolean},
+			"AlarmActions":                     {kind: cfnList},
+			"AlarmDescription":                 {kind: cfnString},
+			"AlarmName":                        {kind: cfnString},
+			"AlarmRule":                        {kind: cfnString, required: true},
+			"ActionsSuppressor":                {kind: cfnString},
+			"ActionsSuppressorExtensionPeriod": {kind: cfnInteger},
+			"ActionsSuppressorWaitPeriod":      {kind: cfnInteger},
+			"InsufficientDataActions":          {kind: cfnList},
+			"OKActions":                        {kind: cfnList},
+			"Tags":                             {kind: cfnList, properties: cfnTagProperties},
+		},
+		"AWS::Logs::MetricFilter": {
+			"FilterName":    {kind: cfnString},
+			"FilterPattern": {kind: cfnString, required: true},
+			"LogGroupName":  {kind: cfnString, required: true},
+			"MetricTransformations": {kind: cfnList, required: true, properties: cfnProperties{
+				"DefaultValue":    {kind: cfnNumber},
+				"Dimensions":      {kind: cfnList, properties: cfnDimensionProperties},
+				"MetricName":      {kind: cfnString, required: true},
+				"MetricNamespace": {kind: cfnString, required: true},
+				"MetricValue":     {kind: cfnString, required: true},
+				"Unit":            {kind: cfnString, enum: cfnUnits},
+			}},
+		},
+	}
+)
+
+// requireValidCloudFormation fails the test if the generated resources (JSON or YAML) violate the specification
+func requireValidCloudFormation(t *testing.T, cf []byte) {
+	t.Helper()
+	require.NoError(t, validateCloudFormation(cf))
+}
+
+// validateCloudFormation returns an error for the first generated resource that violates the specification,
+// resources of types not in the specification are not validated
+func validateCloudFormation(cf []byte) error {
+	var template struct {
+		Resources map[string]struct {
+			Type       string                      `yaml:"Type"`
+			Properties map[interface{}]interface{} `yaml:"Properties"`
+		} `yaml:"Resources"`
+	}
+	if err := yaml.Unmarshal(cf, &template); err != nil {
+		return err
+	}
+
+	logicalIDs := make([]string, 0, len(template.Resources))
+	for logicalID := range template.Resources {
+		logicalIDs = append(logicalIDs, logicalID)
+	}
+	sort.Strings(logicalIDs)
+	for _, logicalID := range logicalIDs {
+		resource := template.Resources[logicalID]
+		properties, found := cfnResourceProperties[resource.Type]
+		if !found {
+			continue
+		}
+		err := validateCfnObject("Properties", resource.Properties, properties)
+		if err == nil && resource.Type == "AWS::CloudWatch::Alarm" {
+			err = validateCfnAlarm(resource.Properties)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "%s (%s)", logicalID, resource.Type)
+		}
+	}
+	return nil
+}
+
+// validateCfnAlarm checks the constraints between the properties of an alarm
+func validateCfnAlarm(properties map[interface{}]interface{}) error {
+	has := func(name string) bool {
+		_, found := properties[name]
+		return found
+	}
+	switch {
+	case has("Metrics") && (has("MetricName") || has("Namespace") || has("Statistic") || has("Period")):
+		return errors.New("Metrics is exclusive of MetricName, Namespace, Statistic and Period")
+	case !has("Metrics") && !has("MetricName"):
+		return errors.New("one of MetricName or Metrics is required")
+	case has("Statistic") && has("ExtendedStatistic"):
+		return errors.New("Statistic is exclusive of ExtendedStatistic")
+	case has("Threshold") && has("ThresholdMetricId"):
+		return errors.New("Threshold is exclusive of ThresholdMetricId")
+	}
+	return nil
+}
+
+func validateCfnObject(path string, object map[interface{}]interface{}, properties cfnProperties) error {
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name.(string))
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, found := properties[name]
+		if !found {
+			return errors.Errorf("%s.%s: unknown property", path, name)
+		}
+		if err := validateCfnValue(path+"."+name, object[name], property); err != nil {
+			return err
+		}
+	}
+	for name, property := range properties {
+		if _, found := object[name]; property.required && !found {
+			return errors.Errorf("%s.%s: missing required property", path, name)
+		}
+	}
+	return nil
+}
+
+func validateCfnValue(path string, value interface{}, property cfnProperty) error {
+	if isCfnIntrinsic(value) && property.kind != cfnObject {
+		return nil // resolved at deploy time
+	}
+	valid := false
+	switch property.kind {
+	case cfnString:
+		var s string
+		if s, valid = value.(string); valid && property.enum != nil && !cfnEnumContains(property.enum, s) {
+			return errors.Errorf("%s: %q is not one of %s", path, s, strings.Join(property.enum, ", "))
+		}
+	case cfnInteger:
+		_, valid = value.(int)
+	case cfnNumber:
+		switch value.(type) {
+		case int, float64:
+			valid = true
+		}
+	case cfnBoolean:
+		_, valid = value.(bool)
+	case cfnList:
+		var items []interface{}
+		if items, valid = value.([]interface{}); valid {
+			for i, item := range items {
+				itemProperty := cfnProperty{kind: cfnString}
+				if property.properties != nil {
+					itemProperty = cfnProperty{kind: cfnObject, properties: property.properties}
+				}
+				if err := validateCfnValue(path+"["+strconv.Itoa(i)+"]", item, itemProperty); err != nil {
+					return err
+				}
+			}
+		}
+	case cfnObject:
+		var object map[interface{}]interface{}
+		if object, valid = value.(map[interface{}]interface{}); valid {
+			return validateCfnObject(path, object, property.properties)
+		}
+	}
+	if !valid {
+		return errors.Errorf("%s: %v has the wrong type", path, value)
+	}
+	return nil
+}
+
+// isCfnIntrinsic returns true for a CF intrinsic function (e.g., Ref), resolved to a value at deploy time
+func isCfnIntrinsic(value interface{}) bool {
+	object, isObject := value.(map[interface{}]interface{})
+	if !isObject || len(object) != 1 {
+		return false
+	}
+	for key := range object {
+		name, _ := key.(string)
+		return name == "Ref" || strings.HasPrefix(name, "Fn::")
+	}
+	return false
+}
+
+func cfnEnumContains(enum []string, value string) bool {
+	for _, allowed := range enum {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestValidateCloudFormation(t *testing.T) {
+	alarm := func() *Alarm {
+		return NewAlarm("MyQueue", "MyAlarm", "has a problem", "my-sns-topic-arn").
+			Metric("AWS/SQS", "ApproximateAgeOfOldestMessage",
+				[]MetricDimension{{Name: "QueueName", Value: map[string]string{"Ref": "MyQueue"}}}).
+			MaxSecondsThreshold(300, 60)
+	}
+	validate := func(alarm *Alarm) error {
+		cf, err := (&Template{Alarms: []*Alarm{alarm}}).Marshal(JSONFormat)
+		require.NoError(t, err)
+		return validateCloudFormation(cf)
+	}
+
+	require.NoError(t, validate(alarm()))
+
+	invalid := alarm()
+	invalid.Properties.ComparisonOperator = "GreaterThan"
+	err := validate(invalid)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `Properties.ComparisonOperator: "GreaterThan" is not one of`)
+
+	invalid = alarm()
+	invalid.Properties.ComparisonOperator = ""
+	err = validate(invalid)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `Properties.ComparisonOperator: "" is not one of`)
+
+	invalid = alarm()
+	invalid.Properties.Unit = "Jiffies"
+	err = validate(invalid)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `Properties.Unit: "Jiffies" is not one of`)
+
+	invalid = alarm()
+	invalid.Properties.Dimensions[0].Value = 1
+	err = validate(invalid)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Properties.Dimensions[0].Value: 1 has the wrong type")
+
+	invalid = alarm()
+	invalid.Properties.ExtendedStatistic = "p99"
+	err = validate(invalid)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Statistic is exclusive of ExtendedStatistic")
+
+	// the YAML format too
+	invalid = alarm()
+	invalid.Properties.TreatMissingData = "notbreaching"
+	cf, err := (&Template{Alarms: []*Alarm{invalid}}).Marshal(YAMLFormat)
+	require.NoError(t, err)
+	err = validateCloudFormation(cf)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `Properties.TreatMissingData: "notbreaching" is not one of`)
+
+	err = validateCloudFormation([]byte(`{"Resources": {"MyFilter": {"Type": "AWS::Logs::MetricFilter",
+		"Properties": {"LogGroupName": "/aws/lambda/my-function", "MetricTransformations": []}}}}`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Properties.FilterPattern: missing required property")
+}
@@ -0,0 +1,127 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ChangedTemplate is a Template of the alarms for only the resources of a CF template added or modified since a
+// baseline template (e.g., to check the alarms of a PR quickly), with the alarms the changes would orphan
+type ChangedTemplate struct {
+	*Template
+	Added          []string // logical ids of the resources not in the baseline, in order
+	Modified       []string // logical ids of the resources that differ from the baseline, in order
+	Removed        []string // logical ids of the resources of the baseline no longer in the template, in order
+	OrphanedAlarms []string // names of the alarms of the removed and modified resources no longer generated, in order
+}
+
+// BuildChangedAlarms returns the alarms for the resources of the CF template in templatePath added or modified since
+// the template in baselinePath, and the names of the alarms of the baseline that should be cleaned up. The unchanged
+// resources are still read, so alarms that depend on other resources (e.g., event source mappings) are the same as
+// for the whole template. Custom and account alarms are not of a resource and are not generated, nor are the alarms
+// of nested stacks.
+func BuildChangedAlarms(config *Config, baselinePath, templatePath string) (*ChangedTemplate, error) {
+	baseline, err := readYamlResources(baselinePath)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := readYamlResources(templatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := &ChangedTemplate{Template: &Template{Description: "Panther Alarms"}}
+	for logicalID, resource := range resources {
+		baselineResource, found := baseline[logicalID]
+		switch {
+		case !found:
+			changed.Added = append(changed.Added, logicalID)
+		case !reflect.DeepEqual(resource, baselineResource):
+			changed.Modified = append(changed.Modified, logicalID)
+		}
+	}
+	for logicalID := range baseline {
+		if _, found := resources[logicalID]; !found {
+			changed.Removed = append(changed.Removed, logicalID)
+		}
+	}
+	sort.Strings(changed.Added)
+	sort.Strings(changed.Modified)
+	sort.Strings(changed.Removed)
+
+	alarms, warnings, err := generateScopedAlarms(config, templatePath, resources, changed.Added, changed.Modified)
+	if err != nil {
+		return nil, err
+	}
+	changed.Alarms, changed.Warnings = alarms, warnings
+
+	// the alarms the baseline generated for the removed and modified resources that are no longer generated
+	baselineAlarms, _, err := generateScopedAlarms(config, baselinePath, baseline, changed.Removed, changed.Modified)
+	if err != nil {
+		return nil, err
+	}
+	generated := make(map[string]struct{}, len(alarms))
+	for _, alarm := range alarms {
+		generated[alarm.Properties.AlarmName] = struct{}{}
+	}
+	for _, alarm := range baselineAlarms {
+		if _, found := generated[alarm.Properties.AlarmName]; !found {
+			changed.OrphanedAlarms = append(changed.OrphanedAlarms, alarm.Properties.AlarmName)
+		}
+	}
+	return changed, nil
+}
+
+// generateScopedAlarms returns the alarms of the CF template in the file for only the resources with the logical ids,
+// ordered by name
+func generateScopedAlarms(config *Config, fileName string, resources cfResources,
+	logicalIDs ...[]string) (alarms []*Alarm, warnings []Warning, err error) {
+
+	scoped := *config
+	scoped.followNestedStacks = false // the nested templates are not compared
+	scoped.scope = make(map[string]struct{})
+	for _, ids := range logicalIDs {
+		for _, logicalID := range ids {
+			scoped.scope[logicalID] = struct{}{}
+		}
+	}
+	alarms, warnings, err = generateResourceAlarms(fileName, resources, &scoped)
+	if err != nil {
+		return nil, nil, err
+	}
+	consumedQueues := make(map[string]struct{})
+	addConsumedQueueNames(consumedQueues, resources)
+	alarms = withoutConsumedQueueAlarms(alarms, consumedQueues)
+	if err = sortAlarms(alarms); err != nil {
+		return nil, nil, err
+	}
+	return alarms, warnings, nil
+}
+
+// inScope returns true if alarms are generated for the resource, all resources are in scope unless limited by
+// BuildChangedAlarms
+func (config *Config) inScope(logicalID string) bool {
+	if config.scope == nil {
+		return true
+	}
+	_, found := config.scope[logicalID]
+	return found
+}
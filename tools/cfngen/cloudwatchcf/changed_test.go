@@ -0,0 +1,78 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildChangedAlarms(t *testing.T) {
+	const (
+		baselinePath = "./testdata/changed/baseline.yml"
+		templatePath = "./testdata/changed/template.yml"
+	)
+	config := NewConfig("my-sns-topic-arn", nil)
+	changed, err := BuildChangedAlarms(config, baselinePath, templatePath)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Stream"}, changed.Added)
+	require.Equal(t, []string{"Queue"}, changed.Modified)
+	require.Equal(t, []string{"Topic"}, changed.Removed)
+
+	// only the alarms of the added and modified resources, alike the alarms generated for the whole template
+	all, err := BuildAlarms(config, templatePath)
+	require.NoError(t, err)
+	var expected []string
+	for _, alarm := range all.Alarms {
+		if alarm.LogicalID == "Queue" || alarm.LogicalID == "Stream" {
+			expected = append(expected, alarm.Properties.AlarmName)
+		}
+	}
+	var generated []string
+	for _, alarm := range changed.Alarms {
+		generated = append(generated, alarm.Properties.AlarmName)
+	}
+	require.NotEmpty(t, generated)
+	require.Equal(t, expected, generated)
+	cf, err := changed.Marshal(JSONFormat)
+	require.NoError(t, err)
+	requireValidCloudFormation(t, cf)
+
+	// the alarms of the removed topic and of the queue under its old name
+	require.Equal(t, []string{
+		"PantherAlarm-SNSError-test-removed-topic-Topic-NumberOfNotificationsFailed-Sum",
+		"PantherAlarm-SNSFilteredOutInvalidAttributes-test-removed-topic-Topic-NumberOfNotificationsFilteredOut-InvalidAttributes-Sum",
+		"PantherAlarm-SQSTooOld-test-queue-Queue-ApproximateAgeOfOldestMessage-Maximum",
+	}, changed.OrphanedAlarms)
+
+	// deterministic
+	again, err := BuildChangedAlarms(config, baselinePath, templatePath)
+	require.NoError(t, err)
+	cfAgain, err := again.Marshal(JSONFormat)
+	require.NoError(t, err)
+	require.Equal(t, string(cf), string(cfAgain))
+	require.Equal(t, changed.OrphanedAlarms, again.OrphanedAlarms)
+
+	// nothing changed, nothing generated
+	unchanged, err := BuildChangedAlarms(config, templatePath, templatePath)
+	require.NoError(t, err)
+	require.Empty(t, unchanged.Alarms)
+	require.Empty(t, unchanged.OrphanedAlarms)
+}
@@ -0,0 +1,258 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigFile is the format of an alarm config file (e.g., generated from a service catalog) in JSON or YAML, e.g.:
+//
+//	overrides:
+//	  LogProcessor:
+//	    Duration: {threshold: 300000, evaluationPeriods: 3}
+//	routes:
+//	  - {tagKey: team, tagValue: security, topic: arn:aws:sns:us-east-1:123456789012:security-alarms}
+//	actions:
+//	  - {logicalId: LogProcessor, metricName: Errors, action: arn:aws:lambda:us-east-1:123456789012:function:restart}
+//
+// The state an action fires on is named state rather than on, which YAML reads as true.
+// Unknown keys, missing required fields (e.g., the topic of a route) and values of the wrong type are rejected.
+type ConfigFile struct {
+	Overrides Overrides    `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+	Routes    AlarmRoutes  `json:"routes,omitempty" yaml:"routes,omitempty"`
+	Actions   AlarmActions `json:"actions,omitempty" yaml:"actions,omitempty"`
+}
+
+// ReadConfigFile reads and validates an alarm config file, an empty fileName returns no config
+func ReadConfigFile(fileName string) (file *ConfigFile, err error) {
+	if fileName == "" {
+		return nil, nil
+	}
+
+	if err = readSchemaFile(fileName, &file); err != nil {
+		return nil, err
+	}
+	if file == nil { // empty file
+		return &ConfigFile{}, nil
+	}
+
+	var problems []string
+	for i := range file.Routes {
+		route := &file.Routes[i]
+		if route.ResourcePrefix == "" && route.TagKey == "" {
+			problems = append(problems, fmt.Sprintf("routes[%d]: requires resourcePrefix or tagKey", i))
+		}
+		route.Topic = cfIntrinsic(route.Topic)
+	}
+	for i := range file.Actions {
+		action := &file.Actions[i]
+		switch action.On {
+		case "", ActionOnAlarm, ActionOnOK, ActionOnBoth:
+		default:
+			problems = append(problems, fmt.Sprintf("actions[%d].state: %s is not one of %s, %s or %s", i, action.On,
+				ActionOnAlarm, ActionOnOK, ActionOnBoth))
+		}
+		action.Action = cfIntrinsic(action.Action)
+	}
+	if len(problems) > 0 {
+		return nil, errors.Errorf("%s: invalid config:\n  %s", fileName, strings.Join(problems, "\n  "))
+	}
+	return file, nil
+}
+
+// ValidateConfigFile checks an alarm config file without generating alarms (e.g., in CI before a deploy), all the
+// problems are reported in the returned error by key path (e.g., overrides.LogProcessor.Duration.threshold)
+func ValidateConfigFile(fileName string) error {
+	if fileName == "" {
+		return errors.New("no config file")
+	}
+	_, err := ReadConfigFile(fileName)
+	return err
+}
+
+// File configures the overrides, routes and actions of the config file, a nil file changes nothing
+func (config *Config) File(file *ConfigFile) *Config {
+	if file == nil {
+		return config
+	}
+	return config.Overrides(file.Overrides).Routes(file.Routes...).Actions(file.Actions...)
+}
+
+// readSchemaFile reads a JSON or YAML file into the value, a pointer, after checking the file matches its type so a
+// malformed file is reported by key path rather than partially decoded
+func readSchemaFile(fileName string, value interface{}) error {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return errors.Wrap(err, fileName)
+	}
+
+	// JSON is a subset of YAML so one parser handles both
+	var yamlObj interface{}
+	if err = yaml.Unmarshal(data, &yamlObj); err != nil {
+		return errors.Wrap(err, fileName)
+	}
+	if problems := schemaProblems("", yamlObj, reflect.TypeOf(value).Elem()); len(problems) > 0 {
+		return errors.Errorf("%s: invalid config:\n  %s", fileName, strings.Join(problems, "\n  "))
+	}
+	return errors.Wrap(yaml.Unmarshal(data, value), fileName)
+}
+
+// schemaProblems returns the differences between the decoded YAML value at the key path and the type. Struct fields
+// are named by their yaml tags and are required unless omitempty. A missing or null value is not checked.
+func schemaProblems(path string, value interface{}, typ reflect.Type) (problems []string) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if value == nil {
+		return nil
+	}
+	mismatch := func(expected string) []string {
+		return []string{schemaProblem(path, "expected %s, got %s", expected, describeYaml(value))}
+	}
+
+	switch typ.Kind() {
+	case reflect.Interface: // e.g., an arn or a CF intrinsic
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			return mismatch("a string")
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return mismatch("a boolean")
+		}
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		if _, ok := value.(int); !ok {
+			return mismatch("an integer")
+		}
+	case reflect.Float32, reflect.Float64:
+		switch value.(type) {
+		case int, float64:
+		default:
+			return mismatch("a number")
+		}
+	case reflect.Slice:
+		list, ok := value.([]interface{})
+		if !ok {
+			return mismatch("a list")
+		}
+		for i, item := range list {
+			problems = append(problems, schemaProblems(fmt.Sprintf("%s[%d]", path, i), item, typ.Elem())...)
+		}
+	case reflect.Map:
+		obj, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return mismatch("a map")
+		}
+		keys, values := yamlEntries(obj)
+		for _, key := range keys {
+			problems = append(problems, schemaProblems(schemaPath(path, key), values[key], typ.Elem())...)
+		}
+	case reflect.Struct:
+		obj, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return mismatch("a map")
+		}
+		return structProblems(path, obj, typ)
+	}
+	return problems
+}
+
+// structProblems returns the unknown keys, missing required fields and fields of the wrong type of the struct
+func structProblems(path string, obj map[interface{}]interface{}, typ reflect.Type) (problems []string) {
+	keys, values := yamlEntries(obj)
+	fields := make(map[string]reflect.StructField)
+	var names []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")
+		if tag[0] == "" || tag[0] == "-" {
+			continue
+		}
+		fields[tag[0]] = field
+		names = append(names, tag[0])
+		if _, found := values[tag[0]]; !found && !containsString(tag[1:], "omitempty") {
+			problems = append(problems, schemaProblem(schemaPath(path, tag[0]), "is required"))
+		}
+	}
+	sort.Strings(names)
+
+	for _, key := range keys {
+		field, found := fields[key]
+		if !found {
+			problems = append(problems, schemaProblem(schemaPath(path, key), "unknown key, expected one of %s",
+				strings.Join(names, ", ")))
+			continue
+		}
+		problems = append(problems, schemaProblems(schemaPath(path, key), values[key], field.Type)...)
+	}
+	return problems
+}
+
+func schemaProblem(path, format string, args ...interface{}) string {
+	if path == "" {
+		return fmt.Sprintf(format, args...)
+	}
+	return path + ": " + fmt.Sprintf(format, args...)
+}
+
+func schemaPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// yamlEntries returns the keys of the YAML map as strings in order, so problems are reported deterministically,
+// and the values by those keys
+func yamlEntries(obj map[interface{}]interface{}) (keys []string, values map[string]interface{}) {
+	values = make(map[string]interface{}, len(obj))
+	for key, value := range obj {
+		keys = append(keys, fmt.Sprintf("%v", key))
+		values[fmt.Sprintf("%v", key)] = value
+	}
+	sort.Strings(keys)
+	return keys, values
+}
+
+// describeYaml returns the type and value of a decoded YAML value for error messages, e.g., string "high"
+func describeYaml(value interface{}) string {
+	switch val := value.(type) {
+	case string:
+		return fmt.Sprintf("string %q", val)
+	case int:
+		return fmt.Sprintf("integer %d", val)
+	case float64:
+		return fmt.Sprintf("number %g", val)
+	case bool:
+		return fmt.Sprintf("boolean %t", val)
+	case []interface{}:
+		return "a list"
+	case map[interface{}]interface{}:
+		return "a map"
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,86 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfigFile(t *testing.T) {
+	file, err := ReadConfigFile("./testdata/config.yml")
+	require.NoError(t, err)
+	threshold, evaluationPeriods, treatMissingData := float32(300000), 3, TreatMissingDataBreaching
+	require.Equal(t, &ConfigFile{
+		Overrides: Overrides{"Function": {
+			"Duration": {Threshold: &threshold, EvaluationPeriods: &evaluationPeriods},
+			"Errors":   {TreatMissingData: &treatMissingData},
+		}},
+		Routes: AlarmRoutes{
+			{TagKey: "panther:alarms", TagValue: "security", Topic: "arn:aws:sns:us-east-1:123456789012:security-alarms"},
+			{ResourcePrefix: "test-ops-", Topic: map[string]interface{}{"Ref": "OpsAlarmsTopic"}},
+		},
+		Actions: AlarmActions{{LogicalID: "Function", MetricName: "Errors", On: ActionOnBoth,
+			Action: map[string]interface{}{"Fn::GetAtt": []interface{}{"RestartFunction", "Arn"}}}},
+	}, file)
+	require.NoError(t, ValidateConfigFile("./testdata/config.yml"))
+
+	config := NewConfig("my-sns-topic-arn", nil).File(file)
+	require.Equal(t, file.Overrides, config.overrides)
+	require.Equal(t, file.Routes, config.routes)
+	require.Equal(t, file.Actions, config.actions)
+
+	// no file, no config
+	file, err = ReadConfigFile("")
+	require.NoError(t, err)
+	require.Nil(t, file)
+	require.Error(t, ValidateConfigFile(""))
+	require.Error(t, ValidateConfigFile("./testdata/missing.yml"))
+}
+
+func TestReadConfigFileUnknownKeys(t *testing.T) {
+	err := ValidateConfigFile("./testdata/config_unknown_key.yml")
+	require.Error(t, err)
+	require.Equal(t, "./testdata/config_unknown_key.yml: invalid config:\n"+
+		"  action: unknown key, expected one of actions, overrides, routes\n"+
+		"  overrides.Function.Duration.treshold: unknown key, expected one of datapointsToAlarm, evaluationPeriods, "+
+		"period, threshold, treatMissingData\n"+
+		"  routes[0].topic: is required\n"+
+		"  routes[0].topics: unknown key, expected one of resourcePrefix, tagKey, tagValue, topic", err.Error())
+}
+
+func TestReadConfigFileTypeMismatch(t *testing.T) {
+	err := ValidateConfigFile("./testdata/config_type_mismatch.yml")
+	require.Error(t, err)
+	require.Equal(t, "./testdata/config_type_mismatch.yml: invalid config:\n"+
+		"  actions[0].state: expected a string, got a list\n"+
+		"  overrides.Function.Duration.period: expected an integer, got number 60.5\n"+
+		"  overrides.Function.Duration.threshold: expected a number, got string \"high\"\n"+
+		"  overrides.Function.Errors: expected a map, got integer 60\n"+
+		"  routes: expected a list, got a map", err.Error())
+
+	// the same checks apply to the overrides and profiles files, rather than decoding them partially
+	_, err = ReadOverrides("./testdata/config.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "overrides.Function.Duration: unknown key, expected one of datapointsToAlarm")
+	_, err = ReadProfiles("./testdata/config.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "actions: expected a map, got a list")
+}
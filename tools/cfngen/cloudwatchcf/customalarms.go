@@ -0,0 +1,110 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/pkg/errors"
+)
+
+const customAlarmType = "Custom"
+
+// CustomAlarm is an alarm on a metric that is not derived from the resources in the CF (e.g., a business metric
+// an application publishes in its own namespace), declared in the config. The alarms are named like the generated
+// alarms, e.g., PantherAlarm-Custom-LogProcessing-EventsProcessed-Sum, and the rest of the config (e.g., the
+// environment, routes and tags) applies to them too.
+type CustomAlarm struct {
+	Name               string // of the alarm, the resource in the runbooks
+	Description        string // "" describes the metric
+	Namespace          string
+	MetricName         string
+	Dimensions         []MetricDimension
+	Statistic          string // e.g., Sum, exclusive of ExtendedStatistic
+	ExtendedStatistic  string // e.g., p99
+	Unit               string // "" for none
+	ComparisonOperator string // "" is GreaterThanThreshold
+	Threshold          float32
+	Period             int    // sec, 0 is 5 min
+	EvaluationPeriods  int    // 0 is 1
+	TreatMissingData   string // "" is TreatMissingDataNotBreaching
+}
+
+// CustomAlarms configures alarms on metrics not derived from the resources in the CF, generated with the resource alarms
+func (config *Config) CustomAlarms(alarms ...CustomAlarm) *Config {
+	config.customAlarms = alarms
+	return config
+}
+
+// generateCustomAlarms returns the configured custom alarms
+func generateCustomAlarms(config *Config) (alarms []*Alarm, err error) {
+	for i := range config.customAlarms {
+		alarm, err := config.customAlarms[i].alarm(config)
+		if err != nil {
+			return nil, err
+		}
+		if err = config.configure(alarm, "", nil); err != nil {
+			return nil, err
+		}
+		alarms = append(alarms, alarm)
+	}
+	return alarms, nil
+}
+
+// alarm returns the alarm for the declaration, or an error if it is incomplete
+func (custom *CustomAlarm) alarm(config *Config) (*Alarm, error) {
+	switch {
+	case custom.Name == "" || custom.Namespace == "" || custom.MetricName == "":
+		return nil, errors.Errorf("custom alarm %q on %s/%s requires a name, namespace and metric name",
+			custom.Name, custom.Namespace, custom.MetricName)
+	case (custom.Statistic == "") == (custom.ExtendedStatistic == ""):
+		return nil, errors.Errorf("custom alarm %s requires one of a statistic or extended statistic", custom.Name)
+	}
+
+	description := custom.Description
+	if description == "" {
+		description = fmt.Sprintf("Custom metric %s/%s crossed its threshold", custom.Namespace, custom.MetricName)
+	}
+	alarm := NewAlarm(custom.Name, AlarmName(customAlarmType, custom.Name),
+		fmt.Sprintf("%s. See: %s#%s", description, documentationURL, custom.Name), config.snsTopicArn).
+		Metric(custom.Namespace, custom.MetricName, custom.Dimensions)
+
+	props := &alarm.Properties
+	threshold := custom.Threshold
+	props.Threshold = &threshold
+	props.ComparisonOperator = custom.ComparisonOperator
+	if props.ComparisonOperator == "" {
+		props.ComparisonOperator = cloudwatch.ComparisonOperatorGreaterThanThreshold
+	}
+	props.Statistic = custom.Statistic
+	props.ExtendedStatistic = custom.ExtendedStatistic
+	props.Unit = custom.Unit
+	props.Period = custom.Period
+	if props.Period == 0 {
+		props.Period = 60 * 5
+	}
+	if custom.EvaluationPeriods > 0 {
+		props.EvaluationPeriods = custom.EvaluationPeriods
+	}
+	props.TreatMissingData = custom.TreatMissingData
+	if props.TreatMissingData == "" {
+		props.TreatMissingData = TreatMissingDataNotBreaching
+	}
+	return alarm, nil
+}
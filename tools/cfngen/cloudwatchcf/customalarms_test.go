@@ -0,0 +1,79 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomAlarms(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", nil).CustomAlarms(CustomAlarm{
+		Name:               "LogProcessing",
+		Description:        "Log processing stopped",
+		Namespace:          "Panther/LogProcessing",
+		MetricName:         "EventsProcessed",
+		Dimensions:         []MetricDimension{{Name: "LogType", Value: "AWS.CloudTrail"}},
+		Statistic:          "Sum",
+		ComparisonOperator: "LessThanThreshold",
+		Threshold:          1,
+		Period:             60 * 15,
+		TreatMissingData:   TreatMissingDataBreaching,
+	})
+	alarms, cf, err := GenerateAlarmsWithConfig(config, "./testdata/firehose.yml")
+	require.NoError(t, err)
+	requireValidCloudFormation(t, cf)
+	require.NoError(t, ValidateAlarms(alarms, "./testdata/firehose.yml"))
+
+	var customAlarm *Alarm
+	for _, alarm := range alarms {
+		if alarm.Properties.Namespace == "Panther/LogProcessing" {
+			require.Nil(t, customAlarm)
+			customAlarm = alarm
+		} else {
+			require.Equal(t, "AWS/Firehose", alarm.Properties.Namespace) // with the resource alarms
+		}
+	}
+	require.NotNil(t, customAlarm)
+	props := customAlarm.Properties
+	require.Equal(t, "PantherAlarm-Custom-LogProcessing-EventsProcessed-Sum", props.AlarmName)
+	require.Equal(t, "Log processing stopped. See: "+documentationURL+"#LogProcessing", props.AlarmDescription)
+	require.Equal(t, "EventsProcessed", props.MetricName)
+	require.Equal(t, []MetricDimension{{Name: "LogType", Value: "AWS.CloudTrail"}}, props.Dimensions)
+	require.Equal(t, "Sum", props.Statistic)
+	require.Equal(t, "LessThanThreshold", props.ComparisonOperator)
+	require.Equal(t, float32(1), *props.Threshold)
+	require.Equal(t, 900, props.Period)
+	require.Equal(t, 1, props.EvaluationPeriods)
+	require.Equal(t, TreatMissingDataBreaching, props.TreatMissingData)
+	require.Equal(t, []interface{}{"my-sns-topic-arn"}, props.AlarmActions)
+
+	// configured like the resource alarms
+	config.Environment("staging", nil).Prefix("deploymentA")
+	alarms, _, err = GenerateAlarmsWithConfig(config)
+	require.NoError(t, err)
+	require.Len(t, alarms, 1)
+	require.Equal(t, "deploymentA-PantherAlarm-staging-Custom-LogProcessing-EventsProcessed-Sum",
+		alarms[0].Properties.AlarmName)
+
+	config.CustomAlarms(CustomAlarm{Name: "LogProcessing", Namespace: "Panther/LogProcessing", MetricName: "EventsProcessed"})
+	_, _, err = GenerateAlarmsWithConfig(config)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "custom alarm LogProcessing requires one of a statistic or extended statistic")
+}
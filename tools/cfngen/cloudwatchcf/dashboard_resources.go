@@ -0,0 +1,216 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+
+	jsoniter "github.com/json-iterator/go"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+const (
+	dashboardWidth  = 24 // CloudWatch dashboards are a grid 24 units wide
+	widgetHeight    = 6
+	widgetPeriod    = 300
+	dashboardRegion = "replace-me" // replaced by NewDashboard()
+)
+
+// enable compatibility with encoding/json, maps are serialized with sorted keys so the body is deterministic
+var json = jsoniter.ConfigCompatibleWithStandardLibrary
+
+// see: https://docs.aws.amazon.com/AmazonCloudWatch/latest/APIReference/CloudWatch-Dashboard-Body-Structure.html
+type DashboardBody struct {
+	Widgets []*DashboardWidget `json:"widgets"`
+}
+
+type DashboardWidget struct {
+	Type       string                    `json:"type"`
+	X          int                       `json:"x"`
+	Y          int                       `json:"y"`
+	Width      int                       `json:"width"`
+	Height     int                       `json:"height"`
+	Properties DashboardWidgetProperties `json:"properties"`
+}
+
+type DashboardWidgetProperties struct {
+	Metrics [][]string `json:"metrics"`
+	View    string     `json:"view"`
+	Region  string     `json:"region"`
+	Title   string     `json:"title"`
+	Stat    string     `json:"stat"`
+	Period  int        `json:"period"`
+}
+
+// dashboardMetric is a metric graphed in a widget
+type dashboardMetric struct {
+	metricName string
+	stat       string
+}
+
+// dashboardRow is the widgets for one resource, laid out side by side
+type dashboardRow struct {
+	resourceType string
+	name         string
+	namespace    string
+	dimension    string
+	metrics      []dashboardMetric
+}
+
+func (row *dashboardRow) widgets(y int) (widgets []*DashboardWidget) {
+	width := dashboardWidth / len(row.metrics)
+	for i, metric := range row.metrics {
+		widgets = append(widgets, &DashboardWidget{
+			Type:   "metric",
+			X:      i * width,
+			Y:      y,
+			Width:  width,
+			Height: widgetHeight,
+			Properties: DashboardWidgetProperties{
+				Metrics: [][]string{{row.namespace, metric.metricName, row.dimension, row.name}},
+				View:    "timeSeries",
+				Region:  dashboardRegion,
+				Title:   row.name + " " + metric.metricName,
+				Stat:    metric.stat,
+				Period:  widgetPeriod,
+			},
+		})
+	}
+	return widgets
+}
+
+// GenerateDashboard will read the CF in yml files in the cfDirs, and generate CF for a CloudWatch dashboard
+// with a row of graphs for each resource in the infrastructure.
+// The dashboard body is a literal, so resources whose names are only known at deploy time (e.g., Refs) are left out.
+func GenerateDashboard(awsRegion, name string, cfDirs ...string) (cf []byte, err error) {
+	return GenerateDashboardWithConfig(NewConfig("", nil), awsRegion, name, cfDirs...)
+}
+
+// GenerateDashboardWithConfig is GenerateDashboard with the resources configured by config (e.g., exclusions).
+func GenerateDashboardWithConfig(config *Config, awsRegion, name string, cfDirs ...string) (cf []byte, err error) {
+	var rows []*dashboardRow
+
+	for _, cfDir := range cfDirs {
+		err := walkYamlFiles(cfDir, func(path string) (err error) {
+			fileRows, err := generateDashboardRows(path, config)
+			if err == nil {
+				rows = append(rows, fileRows...)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// order by type then name so the layout does not depend on the order resources are found
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].resourceType != rows[j].resourceType {
+			return rows[i].resourceType < rows[j].resourceType
+		}
+		return rows[i].name < rows[j].name
+	})
+
+	body := &DashboardBody{
+		Widgets: []*DashboardWidget{}, // serialize as [] rather than null if there are no resources
+	}
+	for i, row := range rows {
+		body.Widgets = append(body.Widgets, row.widgets(i*widgetHeight)...)
+	}
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboard := NewDashboard(awsRegion, config.prefixName(name), string(bodyJSON))
+	resources := map[string]interface{}{
+		cfngen.SanitizeResourceName(dashboard.Properties.DashboardName): dashboard,
+	}
+
+	// generate CF using cfngen
+	return cfngen.NewTemplate("Panther Dashboard", nil, resources, nil).CloudFormation()
+}
+
+func generateDashboardRows(fileName string, config *Config) (rows []*dashboardRow, err error) {
+	resources, err := readYamlResources(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	resources.walk(func(logicalID, resourceType string, resource map[interface{}]interface{}) {
+		if config.exclusions.matches(logicalID, resource) {
+			return
+		}
+		if row := dashboardRowDispatchOnType(logicalID, resourceType, resource); row != nil && row.name != "" {
+			rows = append(rows, row)
+		}
+	})
+
+	return rows, nil
+}
+
+// dashboardResourceName returns the name of the resource, or "" if it is only known at deploy time
+// which cannot be used in the dashboard body
+func dashboardResourceName(key, logicalID string, resource map[interface{}]interface{}) string {
+	_, dimensionValue := getResourceName(key, logicalID, resource)
+	name, _ := dimensionValue.(string)
+	return name
+}
+
+// dispatch on "Type" to create the row of widgets
+func dashboardRowDispatchOnType(logicalID, resourceType string, resource map[interface{}]interface{}) *dashboardRow {
+	switch resourceType {
+	case "AWS::Serverless::Function", "AWS::Lambda::Function": // SAM expands to the same function
+		return &dashboardRow{
+			resourceType: resourceType,
+			name:         dashboardResourceName("FunctionName", logicalID, resource),
+			namespace:    "AWS/Lambda",
+			dimension:    "FunctionName",
+			metrics:      []dashboardMetric{{"Invocations", "Sum"}, {"Errors", "Sum"}, {"Duration", "Maximum"}},
+		}
+	case "AWS::SQS::Queue":
+		return &dashboardRow{
+			resourceType: resourceType,
+			name:         dashboardResourceName("QueueName", logicalID, resource),
+			namespace:    "AWS/SQS",
+			dimension:    "QueueName",
+			metrics: []dashboardMetric{{"ApproximateNumberOfMessagesVisible", "Maximum"},
+				{"ApproximateAgeOfOldestMessage", "Maximum"}},
+		}
+	case "AWS::DynamoDB::Table":
+		return &dashboardRow{
+			resourceType: resourceType,
+			name:         dashboardResourceName("TableName", logicalID, resource),
+			namespace:    "AWS/DynamoDB",
+			dimension:    "TableName",
+			metrics: []dashboardMetric{{"ConsumedReadCapacityUnits", "Sum"}, {"ConsumedWriteCapacityUnits", "Sum"},
+				{"ReadThrottleEvents", "Sum"}, {"WriteThrottleEvents", "Sum"}},
+		}
+	case "AWS::SNS::Topic":
+		return &dashboardRow{
+			resourceType: resourceType,
+			name:         dashboardResourceName("TopicName", logicalID, resource),
+			namespace:    "AWS/SNS",
+			dimension:    "TopicName",
+			metrics:      []dashboardMetric{{"NumberOfMessagesPublished", "Sum"}, {"NumberOfNotificationsFailed", "Sum"}},
+		}
+	}
+	return nil
+}
@@ -51,3 +51,16 @@ func TestDashboards(t *testing.T) {
 	dashboard := NewDashboard(awsRegion, name, dashboardJSON)
 	require.Equal(t, expectedDashboard, dashboard)
 }
+
+func TestGenerateDashboard(t *testing.T) {
+	cf, err := GenerateDashboard("eu-west-1", "TestDashboard", "./testdata/cf.yml")
+	require.NoError(t, err)
+	requireGoldenFile(t, "./testdata/generated_test_dashboard.json", cf)
+
+	// widgets are laid out deterministically, regenerating has the same result
+	for i := 0; i < 10; i++ {
+		regeneratedCf, err := GenerateDashboard("eu-west-1", "TestDashboard", "./testdata/cf.yml")
+		require.NoError(t, err)
+		require.Equal(t, cf, regeneratedCf)
+	}
+}
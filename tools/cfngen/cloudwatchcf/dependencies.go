@@ -0,0 +1,105 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+)
+
+// GenerateMonitoringWithConfig generates the metric filters and the alarms for the CF in the cfDirs as a single
+// template, so alarms on the metrics of the filters can be deployed with them
+func GenerateMonitoringWithConfig(config *Config, cfDirs ...string) (alarms []*Alarm, cf []byte, err error) {
+	template, err := BuildMonitoring(config, cfDirs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	cf, err = template.Marshal(config.format)
+	if err != nil {
+		return nil, nil, err
+	}
+	return template.Alarms, cf, nil
+}
+
+// addDependencies sets the DependsOn of the alarms in the resources to the resources producing the metrics they
+// consume (e.g., metric filters and anomaly detectors), so CF creates the producers first
+func addDependencies(resources map[string]interface{}) {
+	producers := make(map[string][]string) // resource names by metric key
+	for resourceName, resource := range resources {
+		for _, key := range producedMetricKeys(resource) {
+			producers[key] = append(producers[key], resourceName)
+		}
+	}
+
+	for _, resource := range resources {
+		alarm, isAlarm := resource.(*Alarm)
+		if !isAlarm {
+			continue
+		}
+		dependsOn := make(map[string]struct{})
+		for _, key := range alarm.consumedMetricKeys() {
+			for _, resourceName := range producers[key] {
+				dependsOn[resourceName] = struct{}{}
+			}
+		}
+		alarm.DependsOn = nil
+		for resourceName := range dependsOn {
+			alarm.DependsOn = append(alarm.DependsOn, resourceName)
+		}
+		sort.Strings(alarm.DependsOn)
+	}
+}
+
+// producedMetricKeys returns the keys of the metrics the resource produces
+func producedMetricKeys(resource interface{}) (keys []string) {
+	switch producer := resource.(type) {
+	case *MetricFilter:
+		for _, transformation := range producer.Properties.MetricTransformations {
+			keys = append(keys, metricKey(transformation.MetricNamespace, transformation.MetricName, nil))
+		}
+	case *AnomalyDetector:
+		props := producer.Properties
+		keys = append(keys, metricKey(props.Namespace, props.MetricName, props.Dimensions))
+	}
+	return keys
+}
+
+// consumedMetricKeys returns the keys of the metrics the alarm is on, including those of metric data queries
+func (alarm *Alarm) consumedMetricKeys() (keys []string) {
+	props := alarm.Properties
+	if props.MetricName != "" {
+		keys = append(keys, metricKey(props.Namespace, props.MetricName, props.Dimensions))
+	}
+	for _, query := range props.Metrics {
+		if query.MetricStat != nil {
+			metric := query.MetricStat.Metric
+			keys = append(keys, metricKey(metric.Namespace, metric.MetricName, metric.Dimensions))
+		}
+	}
+	return keys
+}
+
+// metricKey identifies a metric by namespace, name and dimensions
+func metricKey(namespace, metricName string, dimensions []MetricDimension) string {
+	key := namespace + "/" + metricName
+	if len(dimensions) > 0 {
+		dimensionsJSON, _ := json.Marshal(dimensions) // map keys are sorted so this is deterministic
+		key += string(dimensionsJSON)
+	}
+	return key
+}
@@ -0,0 +1,59 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateMonitoringDependsOn(t *testing.T) {
+	alarms, cf, err := GenerateMonitoringWithConfig(NewConfig("my-sns-topic-arn", nil), "./testdata/lambda.yml")
+	require.NoError(t, err)
+
+	var template struct {
+		Resources map[string]struct {
+			Type      string
+			DependsOn []string
+		}
+	}
+	require.NoError(t, json.Unmarshal(cf, &template))
+	require.Equal(t, "AWS::Logs::MetricFilter", template.Resources["samfunctionerrors"].Type)
+
+	// the alarm on the metric of the filter is created after the filter
+	const alarmName = "PantherAlarmLambdaApplicationErrorssamfunctionSamFunctionsamfunctionerrorsSum"
+	require.Equal(t, []string{"samfunctionerrors"}, template.Resources[alarmName].DependsOn)
+
+	// alarms on metrics of the monitored resources have no dependencies
+	for _, alarm := range alarms {
+		if alarm.Properties.Namespace != metricFilterNamespace {
+			require.Empty(t, alarm.DependsOn, alarm.Properties.AlarmName)
+		}
+	}
+}
+
+func TestGenerateAlarmsDependsOnMissingProducer(t *testing.T) {
+	// the metric filters are generated in another template so the alarms cannot depend on them
+	alarms, _, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/lambda.yml")
+	require.NoError(t, err)
+	for _, alarm := range alarms {
+		require.Empty(t, alarm.DependsOn, alarm.Properties.AlarmName)
+	}
+}
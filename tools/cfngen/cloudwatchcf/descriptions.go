@@ -0,0 +1,79 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultAlarmDescriptionTemplate renders the description of each alarm type unchanged, e.g.,
+// "Lambda function panther-log-processor is failing. See: https://docs.runpanther.io/operations/runbooks#..."
+const DefaultAlarmDescriptionTemplate = "{{.Description}}"
+
+// AlarmDescriptionData is the context alarm description templates are executed with
+type AlarmDescriptionData struct {
+	LogicalID    string            // of the monitored resource in the CF
+	ResourceType string            // e.g., AWS::Serverless::Function
+	Resource     string            // name of the monitored resource
+	Environment  string            // "" if not configured
+	MetricName   string            // "" for metric math alarms
+	Threshold    *float32          // nil for anomaly detection alarms
+	Tags         map[string]string // CF tags of the monitored resource
+	Description  string            // the default description for the alarm type
+	RunbookURL   string            // where the alarm is documented
+}
+
+// ParseAlarmDescriptionTemplate parses a text/template executed with AlarmDescriptionData for each alarm, e.g.,
+// "{{.Resource}} {{.MetricName}} exceeded {{.Threshold}} - see runbook {{.RunbookURL}}"
+func ParseAlarmDescriptionTemplate(text string) (*template.Template, error) {
+	descriptionTemplate, err := template.New("AlarmDescription").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid alarm description template")
+	}
+	return descriptionTemplate, nil
+}
+
+// describe replaces the description of the alarm with the template executed for the monitored resource
+func (alarm *Alarm) describe(descriptionTemplate *template.Template, environment, resourceType string,
+	resource map[interface{}]interface{}) error {
+
+	if descriptionTemplate == nil {
+		return nil
+	}
+	var description strings.Builder
+	err := descriptionTemplate.Execute(&description, &AlarmDescriptionData{
+		LogicalID:    alarm.LogicalID,
+		ResourceType: resourceType,
+		Resource:     alarm.Resource,
+		Environment:  environment,
+		MetricName:   alarm.Properties.MetricName,
+		Threshold:    alarm.Properties.Threshold,
+		Tags:         getResourceTags(resource),
+		Description:  alarm.Properties.AlarmDescription,
+		RunbookURL:   documentationURL + "#" + alarm.Resource,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "alarm %s description", alarm.Properties.AlarmName)
+	}
+	alarm.Properties.AlarmDescription = description.String()
+	return nil
+}
@@ -0,0 +1,144 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// DiffAlarms generates the alarms for the cfDirs and compares them to the CF generated previously in existingPath
+// without writing anything, the diff describes the added (+), removed (-) and changed (~) resources and changed is
+// true if there are any
+func DiffAlarms(config *Config, existingPath string, cfDirs ...string) (diff string, changed bool, err error) {
+	_, cf, err := GenerateAlarmsWithConfig(config, cfDirs...)
+	if err != nil {
+		return "", false, err
+	}
+	return diffTemplateFile(cf, existingPath)
+}
+
+// DiffMetrics is DiffAlarms for the metric filters generated for the CF template in templatePath
+func DiffMetrics(templatePath, existingPath string) (diff string, changed bool, err error) {
+	cf, err := GenerateMetrics(templatePath)
+	if err != nil {
+		return "", false, err
+	}
+	return diffTemplateFile(cf, existingPath)
+}
+
+// diffTemplateFile structurally compares the resources of the generated CF to the CF in existingPath,
+// so formatting and key order (e.g., JSON vs YAML) do not matter
+func diffTemplateFile(generated []byte, existingPath string) (diff string, changed bool, err error) {
+	existing, err := ioutil.ReadFile(existingPath)
+	if err != nil {
+		return "", false, errors.Wrap(err, existingPath)
+	}
+	existingResources, err := templateResources(existing)
+	if err != nil {
+		return "", false, errors.Wrap(err, existingPath)
+	}
+	generatedResources, err := templateResources(generated)
+	if err != nil {
+		return "", false, err
+	}
+
+	var lines []string
+	for _, name := range sortedKeys(generatedResources, existingResources) {
+		generatedResource, isGenerated := generatedResources[name]
+		existingResource, isExisting := existingResources[name]
+		switch {
+		case !isExisting:
+			lines = append(lines, "+ "+name)
+		case !isGenerated:
+			lines = append(lines, "- "+name)
+		case !reflect.DeepEqual(generatedResource, existingResource):
+			lines = append(lines, "~ "+name)
+			lines = append(lines, diffResource(generatedResource, existingResource)...)
+		}
+	}
+	if len(lines) == 0 {
+		return "", false, nil
+	}
+	return strings.Join(lines, "\n") + "\n", true, nil
+}
+
+// templateResources returns the resources of the JSON or YAML CF keyed by resource name
+func templateResources(cf []byte) (resources map[string]map[string]interface{}, err error) {
+	var template struct {
+		Resources map[string]map[interface{}]interface{} `yaml:"Resources"`
+	}
+	if err = yaml.Unmarshal(cf, &template); err != nil { // JSON is a subset of YAML
+		return nil, err
+	}
+	resources = make(map[string]map[string]interface{}, len(template.Resources))
+	for name, resource := range template.Resources {
+		resources[name] = cfIntrinsic(resource).(map[string]interface{})
+	}
+	return resources, nil
+}
+
+// diffResource describes the changed type and properties of a resource, one line each
+func diffResource(generated, existing map[string]interface{}) (lines []string) {
+	if !reflect.DeepEqual(generated["Type"], existing["Type"]) {
+		lines = append(lines, fmt.Sprintf("    Type: %s -> %s", diffValue(existing["Type"]), diffValue(generated["Type"])))
+	}
+	generatedProperties, _ := generated["Properties"].(map[string]interface{})
+	existingProperties, _ := existing["Properties"].(map[string]interface{})
+	for _, key := range sortedKeys(generatedProperties, existingProperties) {
+		generatedValue, existingValue := generatedProperties[key], existingProperties[key]
+		if !reflect.DeepEqual(generatedValue, existingValue) {
+			lines = append(lines, fmt.Sprintf("    %s: %s -> %s", key, diffValue(existingValue), diffValue(generatedValue)))
+		}
+	}
+	return lines
+}
+
+// diffValue renders a value on one line, absent values are <none>
+func diffValue(value interface{}) string {
+	if value == nil {
+		return "<none>"
+	}
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(valueJSON)
+}
+
+// sortedKeys returns the union of the keys of the maps in order
+func sortedKeys(maps ...interface{}) (keys []string) {
+	seen := make(map[string]struct{})
+	for _, m := range maps {
+		for _, key := range reflect.ValueOf(m).MapKeys() {
+			if _, found := seen[key.String()]; !found {
+				seen[key.String()] = struct{}{}
+				keys = append(keys, key.String())
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,83 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const existingSNSAlarms = "./testdata/generated_test_sns_alarms.json"
+
+func TestDiffAlarmsUnchanged(t *testing.T) {
+	diff, changed, err := DiffAlarms(NewConfig("my-sns-topic-arn", nil), existingSNSAlarms, "./testdata/sns.yml")
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Empty(t, diff)
+}
+
+func TestDiffAlarmsAdded(t *testing.T) {
+	diff, changed, err := DiffAlarms(NewConfig("my-sns-topic-arn", nil), existingSNSAlarms,
+		"./testdata/sns.yml", "./testdata/cloudfront.yml")
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t,
+		"+ PantherAlarmCloudFrontErrorWebDistributionWebDistributionTotalErrorRateAverage\n"+
+			"+ PantherAlarmCloudFrontServerErrorWebDistributionWebDistribution5xxErrorRateAverage\n",
+		diff)
+}
+
+func TestDiffAlarmsRemoved(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", nil).Exclude(&Exclusions{LogicalIDs: []string{"AlertsTopic"}})
+	diff, changed, err := DiffAlarms(config, existingSNSAlarms, "./testdata/sns.yml")
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t,
+		"- PantherAlarmSNSErrortestalertsAlertsTopicNumberOfNotificationsFailedSum\n"+
+			"- PantherAlarmSNSFilteredOutInvalidAttributestestalertsAlertsTopicNumberOfNotificationsFilteredOutInvalidAttributesSum\n",
+		diff)
+}
+
+func TestDiffAlarmsThresholdChanged(t *testing.T) {
+	threshold := float32(5)
+	overrides := Overrides{"AlertsTopic": {"NumberOfNotificationsFailed": {Threshold: &threshold}}}
+	diff, changed, err := DiffAlarms(NewConfig("my-sns-topic-arn", nil).Overrides(overrides), existingSNSAlarms,
+		"./testdata/sns.yml")
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t,
+		"~ PantherAlarmSNSErrortestalertsAlertsTopicNumberOfNotificationsFailedSum\n"+
+			"    Threshold: 0 -> 5\n",
+		diff)
+}
+
+func TestDiffMetrics(t *testing.T) {
+	// the format of the existing CF does not matter
+	diff, changed, err := DiffMetrics("./testdata/cf.yml", "./testdata/generated_test_metrics.yml")
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Empty(t, diff)
+
+	diff, changed, err = DiffMetrics("./testdata/cf.yml", "./testdata/generated_test_intrinsics_metrics.json")
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Contains(t, diff, "+ ")
+	require.Contains(t, diff, "- ")
+}
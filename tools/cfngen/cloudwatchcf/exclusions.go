@@ -0,0 +1,68 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"path"
+)
+
+const (
+	// resources with this CF tag never have metric filters, alarms or dashboard widgets generated
+	monitoringTagKey      = "panther:monitoring"
+	monitoringTagDisabled = "disabled"
+	// resources that are only alarmed on when opted in (e.g., Glue jobs) need this value for the tag
+	monitoringTagEnabled = "enabled"
+)
+
+// Exclusions are resources to skip when generating metric filters, alarms and dashboards.
+// A resource matches if its logical id is in LogicalIDs, its name matches one of the NameGlobs (see path.Match)
+// or it has one of the CF Tags, resources tagged panther:monitoring=disabled always match.
+type Exclusions struct {
+	LogicalIDs []string
+	NameGlobs  []string
+	Tags       map[string]string // tag key -> value
+}
+
+func (exclusions *Exclusions) matches(logicalID string, resource map[interface{}]interface{}) bool {
+	tags := getResourceTags(resource)
+	if tags[monitoringTagKey] == monitoringTagDisabled {
+		return true
+	}
+	if exclusions == nil {
+		return false
+	}
+	for _, excludedID := range exclusions.LogicalIDs {
+		if logicalID == excludedID {
+			return true
+		}
+	}
+	if name := getLiteralResourceName(resource); name != "" {
+		for _, glob := range exclusions.NameGlobs {
+			if matched, _ := path.Match(glob, name); matched { // a malformed glob matches nothing
+				return true
+			}
+		}
+	}
+	for key, value := range exclusions.Tags {
+		if tagValue, found := tags[key]; found && tagValue == value {
+			return true
+		}
+	}
+	return false
+}
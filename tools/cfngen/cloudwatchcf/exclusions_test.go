@@ -0,0 +1,93 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExclusionsTagged(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", nil)
+
+	alarms, alarmsCf, err := GenerateAlarmsWithConfig(config, "./testdata/exclusions.yml")
+	require.NoError(t, err)
+	metricsCf, err := GenerateMetricsWithConfig(config, "./testdata/exclusions.yml")
+	require.NoError(t, err)
+	dashboardCf, err := GenerateDashboardWithConfig(config, "eu-west-1", "TestDashboard", "./testdata/exclusions.yml")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"Function", "LegacyQueue", "Queue", "Table"}, alarmLogicalIDs(alarms))
+	for _, cf := range [][]byte{alarmsCf, metricsCf, dashboardCf} {
+		require.Contains(t, string(cf), "test-lambda")
+		require.NotContains(t, string(cf), "test-idle-lambda")
+		require.NotContains(t, string(cf), "test-migrating-queue")
+	}
+	require.Contains(t, string(dashboardCf), "test-queue")
+}
+
+func TestExclusionsConfigured(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", nil).Exclude(&Exclusions{
+		LogicalIDs: []string{"Table"},
+		NameGlobs:  []string{"test-legacy-*"},
+	})
+
+	alarms, _, err := GenerateAlarmsWithConfig(config, "./testdata/exclusions.yml")
+	require.NoError(t, err)
+	require.Equal(t, []string{"Function", "Queue"}, alarmLogicalIDs(alarms))
+
+	dashboardCf, err := GenerateDashboardWithConfig(config, "eu-west-1", "TestDashboard", "./testdata/exclusions.yml")
+	require.NoError(t, err)
+	require.NotContains(t, string(dashboardCf), "test-legacy-queue")
+	require.NotContains(t, string(dashboardCf), "test-table")
+}
+
+func TestExclusionsMatches(t *testing.T) {
+	resource := map[interface{}]interface{}{
+		"Type": "AWS::SQS::Queue",
+		"Properties": map[interface{}]interface{}{
+			"QueueName": "test-queue",
+			"Tags": []interface{}{
+				map[interface{}]interface{}{"Key": "team", "Value": "migrations"},
+			},
+		},
+	}
+	var noExclusions *Exclusions
+	require.False(t, noExclusions.matches("Queue", resource))
+	require.True(t, (&Exclusions{LogicalIDs: []string{"Queue"}}).matches("Queue", resource))
+	require.True(t, (&Exclusions{NameGlobs: []string{"test-*"}}).matches("Queue", resource))
+	require.False(t, (&Exclusions{NameGlobs: []string{"prod-*"}}).matches("Queue", resource))
+	require.True(t, (&Exclusions{Tags: map[string]string{"team": "migrations"}}).matches("Queue", resource))
+	require.False(t, (&Exclusions{Tags: map[string]string{"team": "platform"}}).matches("Queue", resource))
+}
+
+// alarmLogicalIDs returns the distinct logical ids of the resources of the alarms in sorted order
+func alarmLogicalIDs(alarms []*Alarm) (logicalIDs []string) {
+	found := make(map[string]bool)
+	for _, alarm := range alarms {
+		if !found[alarm.LogicalID] {
+			found[alarm.LogicalID] = true
+			logicalIDs = append(logicalIDs, alarm.LogicalID)
+		}
+	}
+	sort.Strings(logicalIDs)
+	return logicalIDs
+}
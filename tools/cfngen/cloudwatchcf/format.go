@@ -0,0 +1,44 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutputFormat selects how generated CloudFormation is serialized
+type OutputFormat string
+
+const (
+	JSONFormat OutputFormat = "json" // the default
+	YAMLFormat OutputFormat = "yaml" // block style with sorted keys
+)
+
+// ParseOutputFormat maps a flag value (e.g., "json", "yaml" or "yml") to an OutputFormat, "" is JSON
+func ParseOutputFormat(format string) (OutputFormat, error) {
+	switch strings.ToLower(format) {
+	case "", string(JSONFormat):
+		return JSONFormat, nil
+	case string(YAMLFormat), "yml":
+		return YAMLFormat, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q, expected json or yaml", format)
+	}
+}
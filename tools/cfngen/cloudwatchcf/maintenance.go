@@ -0,0 +1,103 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+const (
+	// the companion composite alarm of a suppressed alarm is named like it with this suffix
+	maintenanceAlarmSuffix = "-Maintenance"
+
+	defaultMaintenanceWaitPeriod      = 60 * 2 // sec
+	defaultMaintenanceExtensionPeriod = 60 * 5 // sec
+)
+
+// MaintenanceSuppression holds back the notifications of the alarms for matching resources while a maintenance alarm
+// (e.g., one the deploy pipeline sets to ALARM) is in ALARM, so planned restarts do not page. Each matching alarm gets
+// a companion composite alarm with its actions and the maintenance alarm as the ActionsSuppressor, named like the alarm
+// with a -Maintenance suffix, and the alarm itself no longer notifies.
+// A resource matches if its logical id is in LogicalIDs or it has one of the CF Tags.
+type MaintenanceSuppression struct {
+	Suppressor      interface{} // name or arn of the maintenance alarm, or a CF intrinsic (e.g., Ref of an alarm in the stack)
+	LogicalIDs      []string
+	Tags            map[string]string // tag key -> value
+	WaitPeriod      int               // sec for the suppressor to enter ALARM when an alarm fires, 0 is 2 min
+	ExtensionPeriod int               // sec the suppression continues after the suppressor returns to OK, 0 is 5 min
+}
+
+// SuppressDuringMaintenance configures the alarms for the matching resources to be suppressed while the maintenance
+// alarm is in ALARM, by default no alarms are suppressed
+func (config *Config) SuppressDuringMaintenance(suppression *MaintenanceSuppression) *Config {
+	config.maintenance = suppression
+	return config
+}
+
+func (suppression *MaintenanceSuppression) matches(logicalID string, resource map[interface{}]interface{}) bool {
+	if suppression == nil {
+		return false
+	}
+	for _, suppressedID := range suppression.LogicalIDs {
+		if logicalID == suppressedID {
+			return true
+		}
+	}
+	tags := getResourceTags(resource)
+	for key, value := range suppression.Tags {
+		if tagValue, found := tags[key]; found && tagValue == value {
+			return true
+		}
+	}
+	return false
+}
+
+// suppressDuringMaintenance moves the actions of the alarm to a companion composite alarm suppressed by the maintenance
+// alarm, if the resource of the alarm matches
+func (alarm *Alarm) suppressDuringMaintenance(suppression *MaintenanceSuppression, resource map[interface{}]interface{}) {
+	if !suppression.matches(alarm.LogicalID, resource) {
+		return
+	}
+	waitPeriod, extensionPeriod := suppression.WaitPeriod, suppression.ExtensionPeriod
+	if waitPeriod == 0 {
+		waitPeriod = defaultMaintenanceWaitPeriod
+	}
+	if extensionPeriod == 0 {
+		extensionPeriod = defaultMaintenanceExtensionPeriod
+	}
+
+	props := &alarm.Properties
+	alarm.maintenanceAlarm = &CompositeAlarm{
+		Resource:  alarm.Resource,
+		Type:      "AWS::CloudWatch::CompositeAlarm",
+		DependsOn: []string{cfResourceName(props.AlarmName)}, // the alarm in the rule must exist first
+		Properties: CompositeAlarmProperties{
+			AlarmName:                        props.AlarmName + maintenanceAlarmSuffix,
+			AlarmDescription:                 props.AlarmDescription,
+			ActionsEnabled:                   props.ActionsEnabled,
+			AlarmActions:                     props.AlarmActions,
+			OKActions:                        props.OKActions,
+			InsufficientDataActions:          props.InsufficientDataActions,
+			AlarmRule:                        `ALARM("` + props.AlarmName + `")`,
+			ActionsSuppressor:                suppression.Suppressor,
+			ActionsSuppressorWaitPeriod:      waitPeriod,
+			ActionsSuppressorExtensionPeriod: extensionPeriod,
+			Tags:                             props.Tags,
+		},
+	}
+	props.AlarmActions = nil // only the companion notifies
+	props.OKActions = nil
+	props.InsufficientDataActions = nil
+}
@@ -0,0 +1,101 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+func TestSuppressDuringMaintenance(t *testing.T) {
+	suppressor := cfngen.Ref{Ref: "MaintenanceAlarm"}
+	config := NewConfig("my-sns-topic-arn", nil).SuppressDuringMaintenance(&MaintenanceSuppression{
+		Suppressor: suppressor,
+		Tags:       map[string]string{"panther:alarms": "security"},
+	})
+	alarms, cf, err := GenerateAlarmsWithConfig(config, "./testdata/routes.yml")
+	require.NoError(t, err)
+	requireValidCloudFormation(t, cf)
+
+	// the output is deterministic
+	_, cfAgain, err := GenerateAlarmsWithConfig(config, "./testdata/routes.yml")
+	require.NoError(t, err)
+	require.Equal(t, string(cf), string(cfAgain))
+
+	var template struct {
+		Resources map[string]struct {
+			Type       string
+			DependsOn  []string
+			Properties map[string]interface{}
+		}
+	}
+	require.NoError(t, json.Unmarshal(cf, &template))
+
+	suppressed := 0
+	for _, alarm := range alarms {
+		name := alarm.Properties.AlarmName
+		maintenanceAlarm, found := template.Resources[cfResourceName(name+maintenanceAlarmSuffix)]
+		if alarm.LogicalID != "Function" { // not participating, notifies as usual
+			require.False(t, found, name)
+			require.Equal(t, []interface{}{"my-sns-topic-arn"}, alarm.Properties.AlarmActions, name)
+			continue
+		}
+
+		suppressed++
+		require.True(t, found, name)
+		require.Empty(t, alarm.Properties.AlarmActions, name) // only the companion notifies
+		require.Empty(t, alarm.Properties.OKActions, name)
+		require.Equal(t, "AWS::CloudWatch::CompositeAlarm", maintenanceAlarm.Type)
+		require.Equal(t, []string{cfResourceName(name)}, maintenanceAlarm.DependsOn)
+		require.Equal(t, map[string]interface{}{
+			"AlarmName":                        name + "-Maintenance",
+			"AlarmDescription":                 alarm.Properties.AlarmDescription,
+			"AlarmActions":                     []interface{}{"my-sns-topic-arn"},
+			"AlarmRule":                        `ALARM("` + name + `")`,
+			"ActionsSuppressor":                map[string]interface{}{"Ref": "MaintenanceAlarm"},
+			"ActionsSuppressorWaitPeriod":      float64(120),
+			"ActionsSuppressorExtensionPeriod": float64(300),
+		}, maintenanceAlarm.Properties)
+	}
+	require.Greater(t, suppressed, 1)
+
+	tf := string(TerraformAlarms(alarms))
+	require.Equal(t, suppressed, strings.Count(tf, "resource \"aws_cloudwatch_composite_alarm\""))
+	require.Contains(t, tf, "  actions_suppressor {\n    alarm            = var.MaintenanceAlarm\n")
+
+	// grouped into a composite alarm per resource, the composite alarm is suppressed instead
+	compositeAlarms, cf, err := GenerateCompositeAlarms(alarms)
+	require.NoError(t, err)
+	requireValidCloudFormation(t, cf)
+	require.NotEmpty(t, compositeAlarms)
+	for _, compositeAlarm := range compositeAlarms {
+		if compositeAlarm.Resource == "test-security-lambda" {
+			require.Equal(t, suppressor, compositeAlarm.Properties.ActionsSuppressor)
+			require.Equal(t, []interface{}{"my-sns-topic-arn"}, compositeAlarm.Properties.AlarmActions)
+			require.Equal(t, 120, compositeAlarm.Properties.ActionsSuppressorWaitPeriod)
+		} else {
+			require.Nil(t, compositeAlarm.Properties.ActionsSuppressor, compositeAlarm.Resource)
+		}
+	}
+	require.NotContains(t, string(cf), maintenanceAlarmSuffix)
+}
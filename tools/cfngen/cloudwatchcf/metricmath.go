@@ -0,0 +1,88 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"sort"
+)
+
+const metricMathExpressionID = "e1"
+
+// metricMath is an expression alarmed on in place of the metric of the alarm
+type metricMath struct {
+	expression string
+	label      string
+	metrics    map[string]Metric // by id in the expression
+}
+
+// MetricMath configures the alarm on a metric math expression (e.g., "100 * errors / invocations") of the metrics
+// keyed by their id in the expression, each evaluated with the period and statistic of the threshold, so call after
+// configuring the threshold. The alarm is still named, overridden and routed by its metric (see Metric), which the
+// expression replaces when rendered. Anomaly detection is not supported on expressions (see checkAnomalyDetection).
+// see: https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/using-metric-math.html
+func (alarm *Alarm) MetricMath(expression, label string, metrics map[string]Metric) *Alarm {
+	alarm.metricMath = &metricMath{
+		expression: expression,
+		label:      label,
+		metrics:    metrics,
+	}
+	return alarm
+}
+
+// renderMetricMath replaces the metric of the alarm with the metric data queries of its expression, if any
+func (alarm *Alarm) renderMetricMath() {
+	props := &alarm.Properties
+	if alarm.metricMath == nil || len(props.Metrics) > 0 { // not an expression or already rendered
+		return
+	}
+
+	stat := props.Statistic
+	if props.ExtendedStatistic != "" {
+		stat = props.ExtendedStatistic
+	}
+	ids := make([]string, 0, len(alarm.metricMath.metrics))
+	for id := range alarm.metricMath.metrics {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		props.Metrics = append(props.Metrics, MetricDataQuery{
+			ID: id,
+			MetricStat: &MetricStat{
+				Metric: alarm.metricMath.metrics[id],
+				Period: props.Period,
+				Stat:   stat,
+			},
+		})
+	}
+	props.Metrics = append(props.Metrics, MetricDataQuery{
+		ID:         metricMathExpressionID,
+		Expression: alarm.metricMath.expression,
+		Label:      alarm.metricMath.label,
+		ReturnData: true,
+	})
+
+	// the queries are exclusive of the simple metric fields, the unit of the threshold does not apply to the expression
+	props.Namespace = ""
+	props.MetricName = ""
+	props.Dimensions = nil
+	props.Period = 0
+	props.Unit = ""
+	props.Statistic = ""
+	props.ExtendedStatistic = ""
+}
@@ -0,0 +1,90 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLambdaErrorRateMetricMath(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", nil).LambdaErrorRateThreshold(1)
+	alarms, cf, err := GenerateAlarmsWithConfig(config, "./testdata/lambda.yml")
+	require.NoError(t, err)
+	requireValidCloudFormation(t, cf)
+	require.NoError(t, ValidateAlarms(alarms, "./testdata/lambda.yml"))
+
+	var template struct {
+		Resources map[string]*Alarm
+	}
+	require.NoError(t, json.Unmarshal(cf, &template))
+	alarm := template.Resources["PantherAlarmLambdaErrorRatesamfunctionSamFunctionErrorsSum"]
+	require.NotNil(t, alarm)
+	props := alarm.Properties
+	// the expression replaces the simple metric fields
+	require.Empty(t, props.Namespace)
+	require.Empty(t, props.MetricName)
+	require.Empty(t, props.Dimensions)
+	require.Empty(t, props.Statistic)
+	require.Empty(t, props.Unit)
+	require.Zero(t, props.Period)
+	require.Equal(t, float32(1), *props.Threshold)
+	dimensions := []MetricDimension{{Name: "FunctionName", Value: "sam-function"}}
+	require.Equal(t, []MetricDataQuery{
+		{
+			ID: "errors",
+			MetricStat: &MetricStat{
+				Metric: Metric{Namespace: "AWS/Lambda", MetricName: "Errors", Dimensions: dimensions},
+				Period: 300,
+				Stat:   "Sum",
+			},
+		},
+		{
+			ID: "invocations",
+			MetricStat: &MetricStat{
+				Metric: Metric{Namespace: "AWS/Lambda", MetricName: "Invocations", Dimensions: dimensions},
+				Period: 300,
+				Stat:   "Sum",
+			},
+		},
+		{
+			ID:         "e1",
+			Expression: "100 * errors / invocations",
+			Label:      "ErrorRate",
+			ReturnData: true,
+		},
+	}, props.Metrics)
+
+	// the simple form is kept for the alarms on the error count
+	errorsAlarm := template.Resources["PantherAlarmLambdaErrorssamfunctionSamFunctionErrorsSum"]
+	require.NotNil(t, errorsAlarm)
+	require.Equal(t, "Errors", errorsAlarm.Properties.MetricName)
+	require.Empty(t, errorsAlarm.Properties.Metrics)
+
+	tf := string(TerraformAlarms(alarms))
+	require.Contains(t, tf, `expression  = "100 * errors / invocations"`)
+	require.Contains(t, tf, `label       = "ErrorRate"`)
+
+	// off by default
+	alarms, _, err = GenerateAlarms("my-sns-topic-arn", nil, "./testdata/lambda.yml")
+	require.NoError(t, err)
+	for _, alarm := range alarms {
+		require.Empty(t, alarm.Properties.Metrics)
+	}
+}
@@ -19,7 +19,9 @@ package cloudwatchcf
  */
 
 import (
-	"github.com/panther-labs/panther/tools/cfngen"
+	"io"
+
+	"github.com/pkg/errors"
 )
 
 // https://docs.aws.amazon.com/AmazonCloudWatch/latest/logs/FilterAndPatternSyntax.html
@@ -28,6 +30,7 @@ const (
 
 	lambdaErrorsMetricFilterName = "errors"
 	lambdaWarnsMetricFilterName  = "warns"
+	lambdaPanicsMetricFilterName = "panics"
 	lambdaMemoryMetricFilterName = "memory"
 )
 
@@ -38,7 +41,7 @@ type MetricFilter struct {
 
 type MetricFilterProperties struct {
 	FilterPattern         string
-	LogGroupName          string
+	LogGroupName          interface{} // a string or a CF intrinsic resolved at deploy time
 	MetricTransformations []MetricTransformations
 }
 
@@ -61,6 +64,11 @@ func NewGoLambdaWarnMetricFilter(lambdaName string) *MetricFilter {
 	return NewLambdaMetricFilter(lambdaName, lambdaWarnsMetricFilterName, `{ $.level = "warn" }`, "1")
 }
 
+// NewGoLambdaPanicMetricFilter counts the panic and fatal logs of zap, which end the invocation after logging
+func NewGoLambdaPanicMetricFilter(lambdaName string) *MetricFilter {
+	return NewLambdaMetricFilter(lambdaName, lambdaPanicsMetricFilterName, `{ ($.level = "panic") || ($.level = "fatal") }`, "1")
+}
+
 func NewPythonLambdaWarnMetricFilter(lambdaName string) *MetricFilter {
 	return NewLambdaMetricFilter(lambdaName, lambdaWarnsMetricFilterName, `[ level="[WARN]" ]`, "1")
 }
@@ -93,50 +101,98 @@ func LambdaMetricFilterName(lambdaName, metricName string) string {
 }
 
 // GenerateMetrics will read the CF in yml files in the cfDirs, and generate CF for CloudWatch metric filters for the infrastructure.
-// NOTE: this will not work for resources referenced with Refs, this code requires constant values.
+// Lambda names that are CF intrinsics (e.g., Ref, Fn::Sub) are resolved by CF in the log group of the metric filters.
 func GenerateMetrics(cfDirs ...string) ([]byte, error) {
-	var metricFilters []*MetricFilter
+	return GenerateMetricsWithConfig(NewConfig("", nil), cfDirs...)
+}
+
+// GenerateMetricsWithConfig is GenerateMetrics with the output configured by config (e.g., the format).
+// A cfDir of StdinPath reads the CF from stdin.
+func GenerateMetricsWithConfig(config *Config, cfDirs ...string) ([]byte, error) {
+	template, err := BuildMetrics(config, cfDirs...)
+	if err != nil {
+		return nil, err
+	}
+	return template.Marshal(config.format)
+}
 
-	for _, cfDir := range cfDirs {
-		err := walkYamlFiles(cfDir, func(path string) (err error) {
-			fileMetricFilters, err := generateMetricFilters(path)
-			if err == nil {
-				metricFilters = append(metricFilters, fileMetricFilters...)
+// generateAllMetricFilters returns the metric filters for the CF in the cfDirs, an error if any names collide
+func generateAllMetricFilters(config *Config, cfDirs ...string) (metricFilters []*MetricFilter, err error) {
+	definedBy := make(map[string]string) // resource name -> template
+	err = eachTemplate(cfDirs, func(template *cfTemplate) error {
+		for _, metricFilter := range resourceMetricFilters(template.resources, config) {
+			resourceName := metricFilterResourceName(metricFilter)
+			if otherTemplate, found := definedBy[resourceName]; found {
+				return errors.Errorf("metric filter %s of %s collides with %s", resourceName, template.path, otherTemplate)
 			}
-			return err
-		})
-		if err != nil {
-			return nil, err
+			definedBy[resourceName] = template.path
+			metricFilters = append(metricFilters, metricFilter)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metricFilters, nil
+}
+
+// GenerateMetricsFromReader is GenerateMetricsWithConfig for a single CF template read from the reader
+func GenerateMetricsFromReader(config *Config, reader io.Reader) ([]byte, error) {
+	metricFilters, err := generateMetricFilters(reader, config)
+	if err != nil {
+		return nil, err
 	}
+	template := &Template{
+		Description:   "Panther Metrics",
+		MetricFilters: metricFilters,
+	}
+	return template.Marshal(config.format)
+}
 
-	resources := make(map[string]interface{})
+// metricFilterResources returns the CF resources for the metric filters keyed by resource name
+func metricFilterResources(metricFilters []*MetricFilter) (resources map[string]interface{}) {
+	resources = make(map[string]interface{}, len(metricFilters))
 	for _, metricFilter := range metricFilters {
-		resources[cfngen.SanitizeResourceName(metricFilter.Properties.MetricTransformations[0].MetricName)] = metricFilter
+		resources[metricFilterResourceName(metricFilter)] = metricFilter
 	}
+	return resources
+}
 
-	// generate CF using cfngen
-	return cfngen.NewTemplate("Panther Metrics", nil, resources, nil).CloudFormation()
+func metricFilterResourceName(metricFilter *MetricFilter) string {
+	return cfResourceName(metricFilter.Properties.MetricTransformations[0].MetricName)
 }
 
-func generateMetricFilters(fileName string) (metricFilters []*MetricFilter, err error) {
-	yamlObj, err := readYaml(fileName)
+func generateMetricFilters(reader io.Reader, config *Config) (metricFilters []*MetricFilter, err error) {
+	resources, err := decodeYamlResources(reader)
 	if err != nil {
 		return nil, err
 	}
+	return resourceMetricFilters(resources, config), nil
+}
 
-	walkYamlMap(yamlObj, func(resourceType string, resource map[interface{}]interface{}) {
-		metricFilters = append(metricFilters, metricFilterDispatchOnType(resourceType, resource)...)
+func resourceMetricFilters(resources cfResources, config *Config) (metricFilters []*MetricFilter) {
+	resources.walk(func(logicalID, resourceType string, resource map[interface{}]interface{}) {
+		if config.exclusions.matches(logicalID, resource) || skippedReason(logicalID, resourceType, resource, resources, config) != "" {
+			return // skipped resources are reported with the alarms
+		}
+		for _, metricFilter := range metricFilterDispatchOnType(logicalID, resourceType, resource, config) {
+			for i := range metricFilter.Properties.MetricTransformations {
+				metricFilter.Properties.MetricTransformations[i].MetricNamespace = config.metricNamespace()
+			}
+			metricFilters = append(metricFilters, metricFilter)
+		}
 	})
 
-	return metricFilters, nil
+	return metricFilters
 }
 
 // dispatch on "Type" to create specific metric filters
-func metricFilterDispatchOnType(resourceType string, resource map[interface{}]interface{}) (metricFilters []*MetricFilter) {
+func metricFilterDispatchOnType(logicalID, resourceType string, resource map[interface{}]interface{},
+	config *Config) (metricFilters []*MetricFilter) {
+
 	switch resourceType { // could be a map of key -> func if this gets long
-	case "AWS::Serverless::Function":
-		return generateLambdaMetricFilters(resource)
+	case "AWS::Serverless::Function", "AWS::Lambda::Function": // SAM expands to the same function
+		return generateLambdaMetricFilters(logicalID, resource, config)
 	}
 	return metricFilters
 }
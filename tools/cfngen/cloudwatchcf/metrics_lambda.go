@@ -18,24 +18,92 @@ package cloudwatchcf
  * along with this program.  If not, see <https://www.gnu.org/licenses/>.
  */
 
-func generateLambdaMetricFilters(resource map[interface{}]interface{}) []*MetricFilter {
-	lambdaName := getResourceProperty("FunctionName", resource)
-	runtime := getResourceProperty("Runtime", resource)
-
-	switch runtime {
-	case "go1.x":
-		return []*MetricFilter{
-			NewGoLambdaErrorMetricFilter(lambdaName),
-			NewGoLambdaWarnMetricFilter(lambdaName),
-			NewLambdaMemoryMetricFilter(lambdaName),
-		}
-	case "python3.7":
-		return []*MetricFilter{
-			NewPythonLambdaErrorMetricFilter(lambdaName),
-			NewPythonLambdaWarnMetricFilter(lambdaName),
-			NewLambdaMemoryMetricFilter(lambdaName),
+// lambdaMetricFilterConstructors construct the log metric filters of a Lambda function keyed by metric name
+type lambdaMetricFilterConstructors map[string]func(lambdaName string) *MetricFilter
+
+// lambdaRuntimeMetricFilters construct the log metric filters for each supported Lambda runtime. Functions of a runtime
+// share the same patterns, but CloudWatch metric filters apply to a single log group so each function has its own.
+var lambdaRuntimeMetricFilters = map[string]lambdaMetricFilterConstructors{
+	"go1.x": {
+		lambdaErrorsMetricFilterName: NewGoLambdaErrorMetricFilter,
+		lambdaWarnsMetricFilterName:  NewGoLambdaWarnMetricFilter,
+		lambdaPanicsMetricFilterName: NewGoLambdaPanicMetricFilter,
+		lambdaMemoryMetricFilterName: NewLambdaMemoryMetricFilter,
+	},
+	"python3.7": {
+		lambdaErrorsMetricFilterName: NewPythonLambdaErrorMetricFilter,
+		lambdaWarnsMetricFilterName:  NewPythonLambdaWarnMetricFilter,
+		lambdaMemoryMetricFilterName: NewLambdaMemoryMetricFilter,
+	},
+}
+
+// other runtimes (e.g., nodejs14.x or a container image without a Runtime) only have the metric filter for the REPORT
+// lines of Lambda
+var lambdaDefaultMetricFilters = lambdaMetricFilterConstructors{
+	lambdaMemoryMetricFilterName: NewLambdaMemoryMetricFilter,
+}
+
+// lambdaMetricFilterNames orders the metric filters of a function so the output does not depend on map order
+var lambdaMetricFilterNames = []string{
+	lambdaErrorsMetricFilterName,
+	lambdaWarnsMetricFilterName,
+	lambdaPanicsMetricFilterName,
+	lambdaMemoryMetricFilterName,
+}
+
+// lambdaRuntimeKnown returns true if the log format of the runtime of the function is known
+func lambdaRuntimeKnown(resource map[interface{}]interface{}) bool {
+	runtime, _ := getResourceNestedProperty(resource, "Runtime").(string)
+	_, found := lambdaRuntimeMetricFilters[runtime]
+	return found
+}
+
+// lambdaRuntimeHasMetricFilter returns true if the runtime of the function has a metric filter for the metric (e.g., only
+// Go functions have one for panics)
+func lambdaRuntimeHasMetricFilter(resource map[interface{}]interface{}, metricName string) bool {
+	runtime, _ := getResourceNestedProperty(resource, "Runtime").(string)
+	_, found := lambdaRuntimeMetricFilters[runtime][metricName]
+	return found
+}
+
+// lambdaHasErrorsMetricFilter returns true if the application errors of the function are counted by a metric filter
+func lambdaHasErrorsMetricFilter(logicalID string, resource map[interface{}]interface{}, config *Config) bool {
+	return lambdaRuntimeKnown(resource) || config.lambdaErrorPatternFor(logicalID) != ""
+}
+
+func generateLambdaMetricFilters(logicalID string, resource map[interface{}]interface{},
+	config *Config) (metricFilters []*MetricFilter) {
+
+	lambdaName, lambdaDimension := getResourceName("FunctionName", logicalID, resource)
+	runtime, _ := getResourceNestedProperty(resource, "Runtime").(string)
+
+	newMetricFilters, found := lambdaRuntimeMetricFilters[runtime]
+	if !found {
+		newMetricFilters = lambdaDefaultMetricFilters
+	}
+	// the error log pattern may be configured overall or per function, replacing the default for the runtime
+	errorPattern := config.lambdaErrorPatternFor(logicalID)
+	for _, metricName := range lambdaMetricFilterNames {
+		if metricName == lambdaErrorsMetricFilterName && errorPattern != "" {
+			metricFilters = append(metricFilters, NewLambdaMetricFilter(lambdaName, metricName, errorPattern, "1"))
+		} else if newMetricFilter, hasFilter := newMetricFilters[metricName]; hasFilter {
+			metricFilters = append(metricFilters, newMetricFilter(lambdaName))
 		}
-	default:
-		panic("Unknown lambda runtime: " + runtime)
+	}
+
+	for _, metricFilter := range metricFilters {
+		metricFilter.Properties.LogGroupName = lambdaLogGroupName(lambdaDimension)
+	}
+	return metricFilters
+}
+
+// lambdaLogGroupName returns the log group of the Lambda function, if the name is only known at deploy time so is
+// the log group
+func lambdaLogGroupName(lambdaDimension interface{}) interface{} {
+	if lambdaName, isLiteral := lambdaDimension.(string); isLiteral {
+		return "/aws/lambda/" + lambdaName
+	}
+	return map[string]interface{}{
+		"Fn::Join": []interface{}{"", []interface{}{"/aws/lambda/", lambdaDimension}},
 	}
 }
@@ -19,18 +19,314 @@ package cloudwatchcf
  */
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
 )
 
 func TestGenerateMetrics(t *testing.T) {
 	cf, err := GenerateMetrics("./testdata/cf.yml")
 	require.NoError(t, err)
-	const expectedFile = "./testdata/generated_test_metrics.json"
-	// uncomment to make a new expected file
-	// writeTestFile(cf, expectedFile)
-	expectedCf, err := readTestFile(expectedFile)
+	requireGoldenFile(t, "./testdata/generated_test_metrics.json", cf)
+	requireValidCloudFormation(t, cf)
+}
+
+func TestGenerateMetricsFromReader(t *testing.T) {
+	expectedCf, err := readTestFile("./testdata/generated_test_metrics.json")
+	require.NoError(t, err)
+
+	template, err := os.Open("./testdata/cf.yml")
+	require.NoError(t, err)
+	defer template.Close()
+	cf, err := GenerateMetricsFromReader(NewConfig("", nil), template)
 	require.NoError(t, err)
 	require.Equal(t, expectedCf, cf)
+
+	// the same template piped to stdin
+	_, err = template.Seek(0, 0)
+	require.NoError(t, err)
+	stdin := os.Stdin
+	defer func() { os.Stdin = stdin }()
+	os.Stdin = template
+	cf, err = GenerateMetrics(StdinPath)
+	require.NoError(t, err)
+	require.Equal(t, expectedCf, cf)
+}
+
+func TestGenerateMetricsIntrinsicNames(t *testing.T) {
+	cf, err := GenerateMetrics("./testdata/intrinsics.yml")
+	require.NoError(t, err)
+	requireGoldenFile(t, "./testdata/generated_test_intrinsics_metrics.json", cf)
+}
+
+func TestGenerateMetricsYAML(t *testing.T) {
+	cf, err := GenerateMetricsWithConfig(NewConfig("", nil).Format(YAMLFormat), "./testdata/cf.yml")
+	require.NoError(t, err)
+	requireGoldenFile(t, "./testdata/generated_test_metrics.yml", cf)
+
+	// same structure as the default JSON
+	jsonCf, err := GenerateMetrics("./testdata/cf.yml")
+	require.NoError(t, err)
+	var fromYAML, fromJSON interface{}
+	require.NoError(t, yaml.Unmarshal(cf, &fromYAML))
+	require.NoError(t, yaml.Unmarshal(jsonCf, &fromJSON))
+	require.Equal(t, fromJSON, fromYAML)
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	for flag, expected := range map[string]OutputFormat{"": JSONFormat, "json": JSONFormat, "yaml": YAMLFormat, "YML": YAMLFormat} {
+		format, err := ParseOutputFormat(flag)
+		require.NoError(t, err)
+		require.Equal(t, expected, format)
+	}
+	_, err := ParseOutputFormat("xml")
+	require.Error(t, err)
+}
+
+func TestGenerateLambdaErrorPatternMetrics(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", testStackOutputs()).
+		LambdaErrorPattern(`{ $.level = "ERROR" }`).
+		LambdaErrorPatternFor("Function", `{ $.severity = "error" }`)
+
+	cf, err := GenerateMetricsWithConfig(config, "./testdata/cf.yml")
+	require.NoError(t, err)
+	var metricsCf struct {
+		Resources map[string]*MetricFilter
+	}
+	require.NoError(t, json.Unmarshal(cf, &metricsCf))
+	errorsFilter := metricsCf.Resources["testlambdaerrors"]
+	require.NotNil(t, errorsFilter)
+	require.Equal(t, `{ $.severity = "error" }`, errorsFilter.Properties.FilterPattern)
+	require.Equal(t, "/aws/lambda/test-lambda", errorsFilter.Properties.LogGroupName)
+	require.Equal(t, []MetricTransformations{
+		{
+			DefaultValue:    0,
+			MetricNamespace: metricFilterNamespace,
+			MetricName:      "test-lambda-errors",
+			MetricValue:     "1",
+		},
+	}, errorsFilter.Properties.MetricTransformations)
+
+	alarms, _, err := GenerateAlarmsWithConfig(config, "./testdata/cf.yml")
+	require.NoError(t, err)
+	var errorsAlarm *Alarm
+	for _, alarm := range alarms {
+		if alarm.Properties.Namespace == metricFilterNamespace && alarm.Properties.MetricName == "test-lambda-errors" {
+			errorsAlarm = alarm
+		}
+	}
+	require.NotNil(t, errorsAlarm)
+	require.Equal(t, "PantherAlarm-LambdaApplicationErrors-test-lambda-Function-test-lambda-errors-Sum",
+		errorsAlarm.Properties.AlarmName)
+	require.NoError(t, ValidateAlarms(alarms, "./testdata/cf.yml"))
+}
+
+func TestGenerateLambdaUnknownRuntimeMetrics(t *testing.T) {
+	metricNames := func(config *Config) (filterMetrics, alarmMetrics []string, alarms []*Alarm) {
+		cf, err := GenerateMetricsWithConfig(config, "./testdata/lambda_nodejs.yml")
+		require.NoError(t, err)
+		var metricsCf struct {
+			Resources map[string]*MetricFilter
+		}
+		require.NoError(t, json.Unmarshal(cf, &metricsCf))
+		for _, metricFilter := range metricsCf.Resources {
+			filterMetrics = append(filterMetrics, metricFilter.Properties.MetricTransformations[0].MetricName)
+		}
+		sort.Strings(filterMetrics)
+
+		alarms, _, err = GenerateAlarmsWithConfig(config, "./testdata/lambda_nodejs.yml")
+		require.NoError(t, err)
+		for _, alarm := range alarms {
+			if alarm.Properties.Namespace == metricFilterNamespace {
+				alarmMetrics = append(alarmMetrics, alarm.Properties.MetricName)
+			}
+		}
+		sort.Strings(alarmMetrics)
+		return filterMetrics, alarmMetrics, alarms
+	}
+
+	// only the REPORT lines of Lambda have a known format
+	filterMetrics, alarmMetrics, alarms := metricNames(NewConfig("my-sns-topic-arn", nil))
+	require.Equal(t, []string{"image-function-memory", "node-function-memory"}, filterMetrics)
+	require.Equal(t, filterMetrics, alarmMetrics)
+	require.NoError(t, ValidateAlarms(alarms, "./testdata/lambda_nodejs.yml"))
+
+	// a configured error pattern counts the errors of any runtime
+	filterMetrics, alarmMetrics, _ = metricNames(NewConfig("my-sns-topic-arn", nil).
+		LambdaErrorPatternFor("NodeFunction", `{ $.level = "error" }`))
+	require.Equal(t, []string{"image-function-memory", "node-function-errors", "node-function-memory"}, filterMetrics)
+	require.Equal(t, filterMetrics, alarmMetrics)
+}
+
+func TestGenerateMetricsNamesDifferingByDigit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cloudwatchcf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	var cf strings.Builder
+	cf.WriteString("Resources:\n")
+	for i := 1; i <= 2; i++ {
+		fmt.Fprintf(&cf, "  Function%[1]d:\n    Type: AWS::Serverless::Function\n    Properties:\n"+
+			"      FunctionName: test-lambda-%[1]d\n      Runtime: go1.x\n", i)
+	}
+	cfFile := filepath.Join(dir, "functions.yml")
+	require.NoError(t, ioutil.WriteFile(cfFile, []byte(cf.String()), 0644))
+
+	template, err := BuildMetrics(NewConfig("", nil), cfFile)
+	require.NoError(t, err)
+	require.Len(t, template.MetricFilters, 8)
+	require.Contains(t, metricFilterResources(template.MetricFilters), "testlambda1errors")
+	require.Contains(t, metricFilterResources(template.MetricFilters), "testlambda2errors")
+}
+
+func TestGenerateMetricsNotTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cloudwatchcf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM node:12-alpine\n"), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "empty.yml"), nil, 0644))
+
+	cf, err := GenerateMetrics(dir)
+	require.NoError(t, err)
+	require.NotContains(t, string(cf), "Resources") // nothing to monitor
+}
+
+// writeLargeTemplate writes a template with many functions, each consuming a queue with a dead letter queue, to dir
+func writeLargeTemplate(b *testing.B, dir string) (cfFile string) {
+	const numFunctions = 10000
+	var cf strings.Builder
+	cf.WriteString("AWSTemplateFormatVersion: 2010-09-09\nResources:\n")
+	for i := 0; i < numFunctions; i++ {
+		fmt.Fprintf(&cf, `  Function%[1]d:
+    Type: AWS::Serverless::Function
+    Properties:
+      FunctionName: test-lambda-%[1]d
+      Handler: main
+      MemorySize: 128
+      Runtime: go1.x
+      Timeout: 60
+      Environment:
+        Variables:
+          DEBUG: false
+      Events:
+        Queue:
+          Type: SQS
+          Properties:
+            Queue: !GetAtt Queue%[1]d.Arn
+            BatchSize: 10
+  Queue%[1]d:
+    Type: AWS::SQS::Queue
+    Properties:
+      QueueName: test-queue-%[1]d
+      RedrivePolicy:
+        deadLetterTargetArn: !GetAtt Failures%[1]d.Arn
+        maxReceiveCount: 10
+  Failures%[1]d:
+    Type: AWS::SQS::Queue
+    Properties:
+      QueueName: test-failures-%[1]d
+`, i)
+	}
+	cfFile = filepath.Join(dir, "large.yml")
+	require.NoError(b, ioutil.WriteFile(cfFile, []byte(cf.String()), 0644))
+	return cfFile
+}
+
+func BenchmarkGenerateMetricsLarge(b *testing.B) {
+	dir, err := ioutil.TempDir("", "cloudwatchcf")
+	require.NoError(b, err)
+	defer os.RemoveAll(dir)
+	cfFile := writeLargeTemplate(b, dir)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := GenerateMetrics(cfFile)
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkGenerateAlarmsLarge(b *testing.B) {
+	dir, err := ioutil.TempDir("", "cloudwatchcf")
+	require.NoError(b, err)
+	defer os.RemoveAll(dir)
+	cfFile := writeLargeTemplate(b, dir)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, err := GenerateAlarms("my-sns-topic-arn", nil, cfFile)
+		require.NoError(b, err)
+	}
+}
+
+func TestGenerateLambdaFunctionMetrics(t *testing.T) {
+	cf, err := GenerateMetrics("./testdata/lambda.yml")
+	require.NoError(t, err)
+	// the raw Lambda function has the same metric filters as the SAM function
+	for _, metric := range []string{"errors", "warns", "panics", "memory"} {
+		require.Contains(t, string(cf), `"sam-function-`+metric+`"`)
+		require.Contains(t, string(cf), `"raw-function-`+metric+`"`)
+	}
+}
+
+func TestNewLambdaMetricFilter(t *testing.T) {
+	const lambdaName = "panther-log-processor"
+	testCases := []struct {
+		name            string
+		newMetricFilter func(string) *MetricFilter
+		metricName      string
+		filterPattern   string
+		metricValue     string
+	}{
+		{"GoError", NewGoLambdaErrorMetricFilter, "panther-log-processor-errors", `{ $.level = "error" }`, "1"},
+		{"PythonError", NewPythonLambdaErrorMetricFilter, "panther-log-processor-errors", `[ level="[ERROR]" ]`, "1"},
+		{"GoWarn", NewGoLambdaWarnMetricFilter, "panther-log-processor-warns", `{ $.level = "warn" }`, "1"},
+		{"PythonWarn", NewPythonLambdaWarnMetricFilter, "panther-log-processor-warns", `[ level="[WARN]" ]`, "1"},
+		{"GoPanic", NewGoLambdaPanicMetricFilter, "panther-log-processor-panics",
+			`{ ($.level = "panic") || ($.level = "fatal") }`, "1"},
+		{"Memory", NewLambdaMemoryMetricFilter, "panther-log-processor-memory",
+			`[ report_label="REPORT", ..., label="Used:", max_memory_used_value, unit="MB" ]`, "$max_memory_used_value"},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			metricFilter := testCase.newMetricFilter(lambdaName)
+			require.Equal(t, &MetricFilter{
+				Type: "AWS::Logs::MetricFilter",
+				Properties: MetricFilterProperties{
+					FilterPattern: testCase.filterPattern,
+					LogGroupName:  "/aws/lambda/" + lambdaName,
+					MetricTransformations: []MetricTransformations{{
+						MetricNamespace: "Panther",
+						MetricName:      testCase.metricName,
+						MetricValue:     testCase.metricValue,
+					}},
+				},
+			}, metricFilter)
+
+			// identical inputs are byte identical
+			metricFilterJSON, err := json.Marshal(metricFilter)
+			require.NoError(t, err)
+			otherMetricFilterJSON, err := json.Marshal(testCase.newMetricFilter(lambdaName))
+			require.NoError(t, err)
+			require.Equal(t, metricFilterJSON, otherMetricFilterJSON)
+		})
+	}
+}
+
+func TestGenerateMetricsDeterministic(t *testing.T) {
+	cf, err := GenerateMetrics("./testdata/cf.yml", "./testdata/lambda.yml")
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		otherCf, err := GenerateMetrics("./testdata/cf.yml", "./testdata/lambda.yml")
+		require.NoError(t, err)
+		require.Equal(t, cf, otherCf)
+	}
 }
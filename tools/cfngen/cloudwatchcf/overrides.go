@@ -0,0 +1,69 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// AlarmOverride replaces the default settings of the alarms on a metric, unset fields keep the defaults
+type AlarmOverride struct {
+	Threshold         *float32 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	Period            *int     `json:"period,omitempty" yaml:"period,omitempty"`
+	EvaluationPeriods *int     `json:"evaluationPeriods,omitempty" yaml:"evaluationPeriods,omitempty"`
+	DatapointsToAlarm *int     `json:"datapointsToAlarm,omitempty" yaml:"datapointsToAlarm,omitempty"`
+	TreatMissingData  *string  `json:"treatMissingData,omitempty" yaml:"treatMissingData,omitempty"`
+}
+
+// Overrides are keyed by the logical id of the resource and then the name of the metric,
+// e.g., {"LogProcessor": {"Duration": {"threshold": 300000, "evaluationPeriods": 3}}}
+type Overrides map[string]map[string]*AlarmOverride
+
+// ReadOverrides reads alarm overrides from a JSON or YAML file, an empty fileName returns no overrides.
+// Unknown keys and values of the wrong type are reported by key path (e.g., LogProcessor.Duration.threshold).
+func ReadOverrides(fileName string) (overrides Overrides, err error) {
+	if fileName == "" {
+		return nil, nil
+	}
+
+	if err = readSchemaFile(fileName, &overrides); err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// apply replaces the defaults of the alarm if there is a matching override
+func (overrides Overrides) apply(alarm *Alarm) {
+	override, found := overrides[alarm.LogicalID][alarm.Properties.MetricName]
+	if !found || override == nil {
+		return
+	}
+	if override.Threshold != nil {
+		threshold := *override.Threshold
+		alarm.Properties.Threshold = &threshold
+	}
+	if override.Period != nil {
+		alarm.Properties.Period = *override.Period
+	}
+	if override.EvaluationPeriods != nil {
+		alarm.Properties.EvaluationPeriods = *override.EvaluationPeriods
+	}
+	if override.DatapointsToAlarm != nil {
+		alarm.Properties.DatapointsToAlarm = *override.DatapointsToAlarm
+	}
+	if override.TreatMissingData != nil {
+		alarm.TreatMissingData(*override.TreatMissingData)
+	}
+}
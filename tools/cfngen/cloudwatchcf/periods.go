@@ -0,0 +1,90 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// alarms evaluate standard resolution metrics over periods that are multiples of a minute, high resolution metrics
+// (published with a StorageResolution of 1 sec) may also use these periods
+var highResolutionPeriods = map[int]struct{}{10: {}, 30: {}}
+
+// validPeriod returns true if CloudWatch accepts the period (in sec) for an alarm, 0 is not set
+func validPeriod(period int) bool {
+	_, isHighResolution := highResolutionPeriods[period]
+	return period >= 0 && (isHighResolution || period%60 == 0)
+}
+
+// Period configures the period in sec the metric is evaluated over (EvaluationPeriods is a count of these),
+// call after configuring the threshold
+func (alarm *Alarm) Period(period int) *Alarm {
+	alarm.Properties.Period = period
+	return alarm
+}
+
+// For configures the alarm to fire when the metric breaches the threshold for the duration (e.g., 15 min), computing
+// EvaluationPeriods from the period, call after configuring the threshold. Generating the alarm fails if the duration
+// is not a whole number of periods.
+func (alarm *Alarm) For(duration time.Duration) *Alarm {
+	alarm.Properties.EvaluationPeriods, alarm.buildErr = evaluationPeriodsFor(duration, alarm.Properties.Period)
+	return alarm
+}
+
+// evaluationPeriodsFor returns the count of periods (in sec) spanning the duration, an error if it is not a multiple
+func evaluationPeriodsFor(duration time.Duration, period int) (int, error) {
+	periodDuration := time.Duration(period) * time.Second
+	if period <= 0 || duration <= 0 || duration%periodDuration != 0 {
+		return 0, errors.Errorf("duration %s is not a multiple of the period %ds", duration, period)
+	}
+	return int(duration / periodDuration), nil
+}
+
+// checkPeriods returns an error if the periods of the alarm are not accepted by CloudWatch, or are high resolution
+// periods for metrics not configured as high resolution (see Config.HighResolutionMetrics)
+func (alarm *Alarm) checkPeriods(config *Config) error {
+	props := &alarm.Properties
+	check := func(namespace, metricName string, period int) error {
+		if !validPeriod(period) {
+			return errors.Errorf("alarm %s: period %d is not 10, 30 or a multiple of 60", props.AlarmName, period)
+		}
+		if _, isHighResolution := highResolutionPeriods[period]; isHighResolution {
+			if _, found := config.highResolutionMetrics[namespace+"/"+metricName]; !found {
+				return errors.Errorf("alarm %s: period %d requires %s/%s to be a high resolution metric",
+					props.AlarmName, period, namespace, metricName)
+			}
+		}
+		return nil
+	}
+
+	if err := check(props.Namespace, props.MetricName, props.Period); err != nil {
+		return err
+	}
+	for _, query := range props.Metrics {
+		if query.MetricStat != nil {
+			metric := query.MetricStat.Metric
+			if err := check(metric.Namespace, metric.MetricName, query.MetricStat.Period); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAlarmsPeriods(t *testing.T) {
+	highResolution, lowPriority := 10, 60*5
+	overrides := Overrides{
+		"SamFunction": {"sam-function-errors": {Period: &highResolution}},
+		"RawFunction": {"Throttles": {Period: &lowPriority}},
+	}
+	config := NewConfig("my-sns-topic-arn", nil).Overrides(overrides).HighResolutionMetrics("Panther/sam-function-errors")
+	alarms, _, err := GenerateAlarmsWithConfig(config, "./testdata/lambda.yml")
+	require.NoError(t, err)
+	require.NoError(t, ValidateAlarms(alarms, "./testdata/lambda.yml"))
+
+	periods := make(map[string]int)
+	for _, alarm := range alarms {
+		periods[alarm.LogicalID+"."+alarm.Properties.MetricName] = alarm.Properties.Period
+		if alarm.Properties.MetricName == "sam-function-errors" {
+			alarmJSON, err := json.Marshal(alarm)
+			require.NoError(t, err)
+			// the evaluation periods are a count of periods, not sec
+			require.Contains(t, string(alarmJSON), `"EvaluationPeriods":1,"Period":10,`)
+		}
+	}
+	require.Equal(t, 10, periods["SamFunction.sam-function-errors"])
+	require.Equal(t, 300, periods["RawFunction.Throttles"])
+	require.Equal(t, 300, periods["SamFunction.Throttles"]) // the default
+	require.Equal(t, 300, periods["RawFunction.raw-function-errors"])
+}
+
+func TestGenerateAlarmsInvalidPeriods(t *testing.T) {
+	highResolution, partialMinute := 30, 90
+	config := NewConfig("my-sns-topic-arn", nil).
+		Overrides(Overrides{"SamFunction": {"Errors": {Period: &highResolution}}})
+	_, _, err := GenerateAlarmsWithConfig(config, "./testdata/lambda.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "period 30 requires AWS/Lambda/Errors to be a high resolution metric")
+
+	config.Overrides(Overrides{"SamFunction": {"Errors": {Period: &partialMinute}}})
+	_, _, err = GenerateAlarmsWithConfig(config, "./testdata/lambda.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "period 90 is not 10, 30 or a multiple of 60")
+}
+
+func TestAlarmFor(t *testing.T) {
+	evalPeriods, err := evaluationPeriodsFor(15*time.Minute, 60)
+	require.NoError(t, err)
+	require.Equal(t, 15, evalPeriods)
+
+	_, err = evaluationPeriodsFor(90*time.Second, 60)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "duration 1m30s is not a multiple of the period 60s")
+
+	_, err = evaluationPeriodsFor(15*time.Minute, 0)
+	require.Error(t, err)
+
+	alarm := NewAlarm("MyResource", "MyAlarm", "has errors", "my-sns-topic-arn").
+		SumCountThreshold(0, 60).For(15 * time.Minute)
+	require.NoError(t, alarm.buildErr)
+	require.Equal(t, 15, alarm.Properties.EvaluationPeriods)
+	require.Equal(t, 60, alarm.Properties.Period)
+
+	alarm.For(90 * time.Second)
+	require.Error(t, alarm.buildErr)
+
+	// the raw count of periods is still available
+	alarm.EvaluationPeriods(3)
+	require.Equal(t, 3, alarm.Properties.EvaluationPeriods)
+}
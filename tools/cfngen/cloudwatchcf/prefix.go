@@ -0,0 +1,51 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"strings"
+)
+
+// Prefix configures a prefix for the names of the generated alarms and dashboards and for the custom metric namespace,
+// so the resources of Panther deployments sharing an account are distinct, e.g., a-PantherAlarm-LambdaErrors-... and
+// metrics in a/Panther. The default "" leaves them as is.
+func (config *Config) Prefix(prefix string) *Config {
+	config.prefix = prefix
+	return config
+}
+
+// prefixName returns the name with the configured prefix
+func (config *Config) prefixName(name string) string {
+	if config.prefix == "" {
+		return name
+	}
+	return config.prefix + "-" + name
+}
+
+// metricNamespace returns the namespace of the metrics of the generated metric filters, with the configured prefix
+func (config *Config) metricNamespace() string {
+	if config.prefix == "" {
+		return metricFilterNamespace
+	}
+	return config.prefix + "/" + metricFilterNamespace
+}
+
+// isMetricFilterNamespace returns true if the namespace is of the metrics of generated metric filters, for any prefix
+func isMetricFilterNamespace(namespace string) bool {
+	return namespace == metricFilterNamespace || strings.HasSuffix(namespace, "/"+metricFilterNamespace)
+}
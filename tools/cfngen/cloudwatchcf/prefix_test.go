@@ -0,0 +1,88 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrefix(t *testing.T) {
+	const prefix = "deploymentA"
+	stackOutputs := testStackOutputs()
+	config := NewConfig("my-sns-topic-arn", stackOutputs).Prefix(prefix).LambdaQueryDefinitions(true)
+	alarms, cf, err := GenerateMonitoringWithConfig(config, "./testdata/cf.yml")
+	require.NoError(t, err)
+	require.NoError(t, ValidateAlarms(alarms, "./testdata/cf.yml"))
+
+	var template struct {
+		Resources map[string]struct {
+			Type       string
+			Properties map[string]interface{}
+		}
+	}
+	require.NoError(t, json.Unmarshal(cf, &template))
+	counts := make(map[string]int)
+	for resourceName, resource := range template.Resources {
+		counts[resource.Type]++
+		switch resource.Type {
+		case "AWS::CloudWatch::Alarm":
+			require.True(t, strings.HasPrefix(resource.Properties["AlarmName"].(string), prefix+"-PantherAlarm-"), resourceName)
+			if namespace, found := resource.Properties["Namespace"]; found && !strings.HasPrefix(namespace.(string), "AWS/") {
+				require.Equal(t, prefix+"/Panther", namespace, resourceName)
+			}
+		case "AWS::Logs::MetricFilter":
+			transformations := resource.Properties["MetricTransformations"].([]interface{})
+			require.Equal(t, prefix+"/Panther", transformations[0].(map[string]interface{})["MetricNamespace"], resourceName)
+		case "AWS::Logs::QueryDefinition":
+			require.True(t, strings.HasPrefix(resource.Properties["Name"].(string), prefix+"/Panther/"), resourceName)
+		}
+	}
+	require.NotZero(t, counts["AWS::CloudWatch::Alarm"])
+	require.NotZero(t, counts["AWS::Logs::MetricFilter"])
+	require.NotZero(t, counts["AWS::Logs::QueryDefinition"])
+
+	compositeAlarms, _, err := GenerateCompositeAlarms(alarms)
+	require.NoError(t, err)
+	require.NotEmpty(t, compositeAlarms)
+	for _, compositeAlarm := range compositeAlarms {
+		require.True(t, strings.HasPrefix(compositeAlarm.Properties.AlarmName, prefix+"-PantherAlarm-Composite-"))
+	}
+
+	// the prefix stays first for the alarms of nested stacks
+	nestedAlarms, _, err := GenerateAlarmsWithConfig(NewConfig("my-sns-topic-arn", nil).Prefix(prefix).FollowNestedStacks(true),
+		"./testdata/nested/parent.yml")
+	require.NoError(t, err)
+	require.NotEmpty(t, nestedAlarms)
+	for _, alarm := range nestedAlarms {
+		require.True(t, strings.HasPrefix(alarm.Properties.AlarmName, prefix+"-"), alarm.Properties.AlarmName)
+	}
+
+	cf, err = GenerateDashboardWithConfig(config, "eu-west-1", "TestDashboard", "./testdata/cf.yml")
+	require.NoError(t, err)
+	require.Contains(t, string(cf), `"DashboardName": "deploymentA-TestDashboard-eu-west-1"`)
+
+	// no prefix is the default
+	_, expectedCf, err := GenerateAlarms("my-sns-topic-arn", stackOutputs, "./testdata/cf.yml")
+	require.NoError(t, err)
+	_, cf, err = GenerateAlarmsWithConfig(NewConfig("my-sns-topic-arn", stackOutputs).Prefix(""), "./testdata/cf.yml")
+	require.NoError(t, err)
+	require.Equal(t, expectedCf, cf)
+}
@@ -0,0 +1,87 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// DefaultProfile is the profile used for environments without their own
+const DefaultProfile = "default"
+
+// Profile configures the alarms for an environment (e.g., looser thresholds and no paging in dev)
+type Profile struct {
+	SNSTopicArn    string    `json:"snsTopicArn,omitempty" yaml:"snsTopicArn,omitempty"`       // "" keeps the default topic
+	ThresholdScale float32   `json:"thresholdScale,omitempty" yaml:"thresholdScale,omitempty"` // of default thresholds, 0 is 1
+	Overrides      Overrides `json:"overrides,omitempty" yaml:"overrides,omitempty"`           // replace the profile thresholds
+}
+
+// Profiles are keyed by environment, e.g.:
+//
+//	{"dev": {"snsTopicArn": "arn:aws:sns:us-east-1:123456789012:noop", "thresholdScale": 2}, "default": {}}
+type Profiles map[string]*Profile
+
+// ReadProfiles reads environment profiles from a JSON or YAML file, an empty fileName returns no profiles.
+// Unknown keys and values of the wrong type are reported by key path (e.g., dev.thresholdScale).
+func ReadProfiles(fileName string) (profiles Profiles, err error) {
+	if fileName == "" {
+		return nil, nil
+	}
+
+	if err = readSchemaFile(fileName, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// profile returns the profile for the environment, falling back to the DefaultProfile which may be nil
+func (profiles Profiles) profile(environment string) *Profile {
+	if profile, found := profiles[environment]; found {
+		return profile
+	}
+	return profiles[DefaultProfile]
+}
+
+// applyDefaults adjusts the default settings of the alarm for the environment, before any overrides
+func (profile *Profile) applyDefaults(alarm *Alarm) {
+	if profile == nil {
+		return
+	}
+	if profile.ThresholdScale > 0 && alarm.Properties.Threshold != nil {
+		threshold := *alarm.Properties.Threshold * profile.ThresholdScale
+		alarm.Properties.Threshold = &threshold
+	}
+	if profile.SNSTopicArn != "" {
+		alarm.Properties.AlarmActions = []interface{}{profile.SNSTopicArn}
+	}
+}
+
+// applyOverrides replaces the settings of the alarm with the overrides of the environment
+func (profile *Profile) applyOverrides(alarm *Alarm) {
+	if profile == nil {
+		return
+	}
+	profile.Overrides.apply(alarm)
+}
+
+// qualifyEnvironment folds the environment into the name and description of the alarm so the alarms of environments
+// sharing an account do not collide, e.g., PantherAlarm-dev-LambdaErrors-...
+func (alarm *Alarm) qualifyEnvironment(environment string) {
+	if environment == "" {
+		return
+	}
+	alarm.Properties.AlarmName = alarmPrefix + "-" + environment + alarm.Properties.AlarmName[len(alarmPrefix):]
+	alarm.Properties.AlarmDescription = "[" + environment + "] " + alarm.Properties.AlarmDescription
+}
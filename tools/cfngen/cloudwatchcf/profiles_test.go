@@ -0,0 +1,73 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAlarmsEnvironment(t *testing.T) {
+	profiles, err := ReadProfiles("./testdata/profiles.yml")
+	require.NoError(t, err)
+
+	thresholds := func(environment string) map[string]float32 {
+		config := NewConfig("my-sns-topic-arn", nil).Environment(environment, profiles)
+		alarms, _, err := GenerateAlarmsWithConfig(config, "./testdata/lambda.yml")
+		require.NoError(t, err)
+		thresholds := make(map[string]float32)
+		for _, alarm := range alarms {
+			require.True(t, strings.HasPrefix(alarm.Properties.AlarmName, "PantherAlarm-"+environment+"-"),
+				alarm.Properties.AlarmName)
+			require.True(t, strings.HasPrefix(alarm.Properties.AlarmDescription, "["+environment+"] "),
+				alarm.Properties.AlarmDescription)
+			if environment == "dev" {
+				require.Equal(t, []interface{}{"arn:aws:sns:us-east-1:123456789012:panther-noop"},
+					alarm.Properties.AlarmActions)
+			} else {
+				require.Equal(t, []interface{}{"my-sns-topic-arn"}, alarm.Properties.AlarmActions)
+			}
+			thresholds[alarm.LogicalID+"."+alarm.Properties.MetricName] = *alarm.Properties.Threshold
+		}
+		return thresholds
+	}
+
+	prod, dev := thresholds("prod"), thresholds("dev")
+	require.Equal(t, float32(1), prod["SamFunction.Throttles"])
+	require.Equal(t, float32(5), prod["RawFunction.Throttles"])
+	require.Equal(t, float32(20), dev["SamFunction.Throttles"])
+	require.Equal(t, float32(0), dev["SamFunction.Errors"]) // scaling does not loosen zero thresholds
+	require.Equal(t, 4*prod["RawFunction.Duration"], dev["RawFunction.Duration"])
+
+	// unknown environments use the default profile
+	staging := thresholds("staging")
+	require.Equal(t, float32(5), staging["SamFunction.Throttles"])
+}
+
+func TestGenerateAlarmsNoEnvironment(t *testing.T) {
+	alarms, cf, err := GenerateAlarmsWithConfig(NewConfig("my-sns-topic-arn", nil).Environment("", nil),
+		"./testdata/sns.yml")
+	require.NoError(t, err)
+	require.NotEmpty(t, alarms)
+	expectedCf, err := readTestFile("./testdata/generated_test_sns_alarms.json")
+	require.NoError(t, err)
+	require.Equal(t, expectedCf, cf)
+}
@@ -0,0 +1,89 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+// DefaultLambdaQuery finds the recent errors of a Lambda function, including stack traces and Python tracebacks
+const DefaultLambdaQuery = `fields @timestamp, @message, @logStream
+| filter @message like /(?i)(error|panic|traceback)/
+| sort @timestamp desc
+| limit 100`
+
+// see: https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-resource-logs-querydefinition.html
+type QueryDefinition struct {
+	Type       string
+	Properties QueryDefinitionProperties
+}
+
+type QueryDefinitionProperties struct {
+	Name          string        // the slashes group the queries in the console, e.g., Panther/panther-log-processor/errors
+	LogGroupNames []interface{} // strings or CF intrinsics resolved at deploy time
+	QueryString   string
+}
+
+func NewLambdaQueryDefinition(lambdaName string, lambdaDimension interface{}, query string) *QueryDefinition {
+	return &QueryDefinition{
+		Type: "AWS::Logs::QueryDefinition",
+		Properties: QueryDefinitionProperties{
+			Name:          metricFilterNamespace + "/" + lambdaName + "/errors",
+			LogGroupNames: []interface{}{lambdaLogGroupName(lambdaDimension)},
+			QueryString:   query,
+		},
+	}
+}
+
+// generateQueryDefinitions returns the Logs Insights queries for the Lambda functions in the CF of the cfDirs keyed by
+// resource name, if configured
+func generateQueryDefinitions(config *Config, cfDirs ...string) (queryDefinitions map[string]*QueryDefinition, err error) {
+	if !config.lambdaQueryDefinitions {
+		return nil, nil
+	}
+	query := config.lambdaQuery
+	if query == "" {
+		query = DefaultLambdaQuery
+	}
+
+	queryDefinitions = make(map[string]*QueryDefinition)
+	for _, cfDir := range cfDirs {
+		err := walkYamlFiles(cfDir, func(path string) error {
+			fileResources, err := readYamlResources(path)
+			if err != nil {
+				return err
+			}
+			fileResources.walk(func(logicalID, resourceType string, resource map[interface{}]interface{}) {
+				switch resourceType {
+				case "AWS::Serverless::Function", "AWS::Lambda::Function":
+				default:
+					return
+				}
+				if config.exclusions.matches(logicalID, resource) {
+					return
+				}
+				lambdaName, lambdaDimension := getResourceName("FunctionName", logicalID, resource)
+				queryDefinition := NewLambdaQueryDefinition(lambdaName, lambdaDimension, query)
+				queryDefinition.Properties.Name = config.metricNamespace() + "/" + lambdaName + "/errors"
+				queryDefinitions[cfResourceName("PantherQuery-"+lambdaName)] = queryDefinition
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return queryDefinitions, nil
+}
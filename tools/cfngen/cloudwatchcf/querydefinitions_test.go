@@ -0,0 +1,72 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateLambdaQueryDefinitions(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", nil).LambdaQueryDefinitions(true)
+	_, cf, err := GenerateAlarmsWithConfig(config, "./testdata/lambda.yml", "./testdata/intrinsics.yml")
+	require.NoError(t, err)
+
+	var template struct {
+		Resources map[string]*QueryDefinition
+	}
+	require.NoError(t, json.Unmarshal(cf, &template))
+	require.Equal(t, &QueryDefinition{
+		Type: "AWS::Logs::QueryDefinition",
+		Properties: QueryDefinitionProperties{
+			Name:          "Panther/sam-function/errors",
+			LogGroupNames: []interface{}{"/aws/lambda/sam-function"},
+			QueryString:   DefaultLambdaQuery,
+		},
+	}, template.Resources["PantherQuerysamfunction"])
+	require.Equal(t, []interface{}{"/aws/lambda/raw-function"},
+		template.Resources["PantherQueryrawfunction"].Properties.LogGroupNames)
+
+	// the log group of a function named at deploy time is too
+	require.Equal(t, []interface{}{map[string]interface{}{"Fn::Join": []interface{}{"", []interface{}{
+		"/aws/lambda/", map[string]interface{}{"Fn::Sub": "${AWS::StackName}-log-processor"}}}}},
+		template.Resources["PantherQueryLogProcessorFunction"].Properties.LogGroupNames)
+
+	var queryDefinitions int
+	for _, resource := range template.Resources {
+		if resource.Type == "AWS::Logs::QueryDefinition" {
+			queryDefinitions++
+		}
+	}
+	require.Equal(t, 3, queryDefinitions)
+}
+
+func TestGenerateLambdaQueryDefinitionsConfigured(t *testing.T) {
+	const query = `fields @timestamp, msg, stacktrace | filter level = "error"`
+	config := NewConfig("my-sns-topic-arn", nil).LambdaQueryDefinitions(true).LambdaQuery(query)
+	_, cf, err := GenerateAlarmsWithConfig(config, "./testdata/lambda.yml")
+	require.NoError(t, err)
+	require.Contains(t, string(cf), `"QueryString": "fields @timestamp, msg, stacktrace | filter level = \"error\""`)
+
+	// off by default
+	_, cf, err = GenerateAlarms("my-sns-topic-arn", nil, "./testdata/lambda.yml")
+	require.NoError(t, err)
+	require.NotContains(t, string(cf), "AWS::Logs::QueryDefinition")
+}
@@ -0,0 +1,58 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"strings"
+)
+
+// AlarmRoute sends the notifications of alarms for matching resources to a topic other than the default.
+// A resource matches if its name starts with ResourcePrefix or it has the CF tag TagKey (with TagValue, if set).
+type AlarmRoute struct {
+	ResourcePrefix string      `json:"resourcePrefix,omitempty" yaml:"resourcePrefix,omitempty"`
+	TagKey         string      `json:"tagKey,omitempty" yaml:"tagKey,omitempty"`
+	TagValue       string      `json:"tagValue,omitempty" yaml:"tagValue,omitempty"`
+	Topic          interface{} `json:"topic" yaml:"topic"` // topic arn or a CF intrinsic (e.g., cfngen.Ref to a topic in the stack)
+}
+
+func (route *AlarmRoute) matches(alarm *Alarm, resource map[interface{}]interface{}) bool {
+	if route.ResourcePrefix != "" && strings.HasPrefix(alarm.Resource, route.ResourcePrefix) {
+		return true
+	}
+	if route.TagKey != "" {
+		tagValue, found := getResourceTags(resource)[route.TagKey]
+		return found && (route.TagValue == "" || route.TagValue == tagValue)
+	}
+	return false
+}
+
+// AlarmRoutes are checked in order, the first matching route is used
+type AlarmRoutes []AlarmRoute
+
+// apply sends all the notifications of the alarm to the topic of the first matching route
+func (routes AlarmRoutes) apply(alarm *Alarm, resource map[interface{}]interface{}) {
+	for i := range routes {
+		if routes[i].matches(alarm, resource) {
+			alarm.Properties.AlarmActions = []interface{}{routes[i].Topic}
+			alarm.Properties.OKActions = []interface{}{routes[i].Topic}
+			alarm.Properties.InsufficientDataActions = []interface{}{routes[i].Topic}
+			return
+		}
+	}
+}
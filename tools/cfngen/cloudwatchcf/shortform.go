@@ -0,0 +1,448 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// The YAML decoder drops unknown tags, so without help !Ref Queue decodes as "Queue" and !Sub ${AWS::StackName}-x
+// as a literal name. The short forms are rewritten to the long forms before decoding so both decode alike, see:
+// https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/intrinsic-function-reference.html
+
+// shortFormFunctions maps the short form tags to the keys of the long forms
+var shortFormFunctions = map[string]string{
+	"Ref":         "Ref",
+	"Condition":   "Condition",
+	"And":         "Fn::And",
+	"Base64":      "Fn::Base64",
+	"Cidr":        "Fn::Cidr",
+	"Equals":      "Fn::Equals",
+	"FindInMap":   "Fn::FindInMap",
+	"GetAtt":      "Fn::GetAtt",
+	"GetAZs":      "Fn::GetAZs",
+	"If":          "Fn::If",
+	"ImportValue": "Fn::ImportValue",
+	"Join":        "Fn::Join",
+	"Not":         "Fn::Not",
+	"Or":          "Fn::Or",
+	"Select":      "Fn::Select",
+	"Split":       "Fn::Split",
+	"Sub":         "Fn::Sub",
+	"Transform":   "Fn::Transform",
+}
+
+var (
+	// e.g., "key: |" or "- >-", the lines indented more are the content of the block scalar
+	blockScalarRegex = regexp.MustCompile(`(^|[:-]|![A-Za-z]+)\s+[|>][-+1-9]*\s*(#.*)?$`)
+	// the rest of a line after a tag whose node is on the following lines
+	blockNodeRegex = regexp.MustCompile(`^([|>][-+1-9]*\s*)?(#.*)?$`)
+)
+
+// shortFormBlock is a tag on a line whose node is a block on the following lines (e.g., "Layers: !If"), those lines
+// are indented 2 more to nest under the long form key
+type shortFormBlock struct {
+	indent     int  // lines indented more belong to the node
+	compactSeq bool // a sequence at indent belongs to the node, as it is the value of a mapping key
+}
+
+func (block *shortFormBlock) contains(indent int, content string) bool {
+	return indent > block.indent ||
+		(block.compactSeq && indent == block.indent && (content == "-" || strings.HasPrefix(content, "- ")))
+}
+
+// shortFormReader rewrites the short form intrinsic function tags (e.g., !Ref Queue) in the YAML CF read from the
+// reader to the long forms (e.g., {"Ref": "Queue"}), leaving the rest as is. The lines are rewritten as they are
+// read so the template is not buffered.
+type shortFormReader struct {
+	reader            *bufio.Reader
+	expanded          []byte            // of the lines read, not yet returned
+	err               error             // of reading the next line
+	blocks            []*shortFormBlock // enclosing the current line
+	blockScalarIndent int               // while >= 0, the lines indented more are the content of a block scalar
+}
+
+func newShortFormReader(reader io.Reader) io.Reader {
+	return &shortFormReader{reader: bufio.NewReader(reader), blockScalarIndent: -1}
+}
+
+func (reader *shortFormReader) Read(p []byte) (n int, err error) {
+	for len(reader.expanded) == 0 {
+		if reader.err != nil {
+			return 0, reader.err
+		}
+		var line string
+		line, reader.err = reader.reader.ReadString('\n')
+		if line == "" {
+			continue
+		}
+		newline := strings.HasSuffix(line, "\n")
+		line = trimLineBreak(line)
+		// a flow collection on the following lines is rewritten with the line, e.g., "A: [ !Ref B,\n  !Ref C ]"
+		for newline && reader.err == nil && !reader.blockScalarContent(line) && flowCollectionOpen(line) {
+			var next string
+			next, reader.err = reader.reader.ReadString('\n')
+			if next == "" {
+				break
+			}
+			newline = strings.HasSuffix(next, "\n")
+			line += "\n" + trimLineBreak(next)
+		}
+		expanded := strings.Join(reader.expandLine(line), "\n")
+		if newline {
+			expanded += "\n"
+		}
+		reader.expanded = []byte(expanded)
+	}
+	n = copy(p, reader.expanded)
+	reader.expanded = reader.expanded[n:]
+	return n, nil
+}
+
+// expandLine returns the lines rewriting the line, a tag whose node is a block on the following lines nests them
+// under the long form key
+func (reader *shortFormReader) expandLine(line string) (expanded []string) {
+	content := strings.TrimLeft(line, " ")
+	indent := len(line) - len(content)
+	if strings.TrimSpace(content) == "" {
+		return []string{line}
+	}
+	for len(reader.blocks) > 0 && !reader.blocks[len(reader.blocks)-1].contains(indent, content) {
+		reader.blocks = reader.blocks[:len(reader.blocks)-1]
+	}
+	shift := strings.Repeat(" ", 2*len(reader.blocks))
+
+	if reader.blockScalarContent(line) {
+		return []string{shift + line}
+	}
+	reader.blockScalarIndent = -1
+	if strings.ContainsAny(line, "|>") && blockScalarRegex.MatchString(line) { // the regex is costly, most lines have no indicator
+		reader.blockScalarIndent = indent
+	}
+
+	prefix, tagIndex, function, rest := expandShortFormNodes(line)
+	if function == "" {
+		return []string{shift + strings.ReplaceAll(prefix, "\n", "\n"+shift)}
+	}
+
+	// the node of the last tag is a block
+	if rest != "" {
+		rest = " " + rest
+	}
+	trimmedPrefix := strings.TrimRight(prefix, " ")
+	if strings.HasSuffix(trimmedPrefix, ":") { // "key: !Tag" becomes "key:" with the long form key nested under it
+		keyIndent := indent
+		for keyIndent < len(line) && (line[keyIndent] == '-' || line[keyIndent] == ' ') {
+			keyIndent++
+		}
+		reader.blocks = append(reader.blocks, &shortFormBlock{indent: keyIndent, compactSeq: true})
+		return []string{shift + trimmedPrefix, shift + strings.Repeat(" ", keyIndent+2) + function + ":" + rest}
+	}
+	// "- !Tag" or "!Tag" becomes a mapping with the long form key in place of the tag
+	blockIndent := strings.LastIndexByte(line[:tagIndex], '-')
+	if blockIndent < 0 {
+		blockIndent = tagIndex
+	}
+	reader.blocks = append(reader.blocks, &shortFormBlock{indent: blockIndent})
+	return []string{shift + prefix + function + ":" + rest}
+}
+
+// blockScalarContent returns whether the line is the content of a block scalar, which is left as is
+func (reader *shortFormReader) blockScalarContent(line string) bool {
+	content := strings.TrimLeft(line, " ")
+	return reader.blockScalarIndent >= 0 && len(line)-len(content) > reader.blockScalarIndent && strings.TrimSpace(content) != ""
+}
+
+// trimLineBreak returns the line without its line break, "\n" or "\r\n"
+func trimLineBreak(line string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+}
+
+// flowCollectionOpen returns whether a flow collection in s (e.g., "A: [ !Ref B,") does not end in s
+func flowCollectionOpen(s string) bool {
+	if strings.IndexAny(s, "[{") < 0 { // most lines have no flow collection
+		return false
+	}
+	depth := 0
+	nodeStart := true // a node (e.g., a flow collection or quoted scalar) may start here
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isSpace(c):
+			continue
+		case commentStart(s, i): // the comment ends with the line
+			if end := strings.IndexByte(s[i:], '\n'); end >= 0 {
+				i += end
+				continue
+			}
+			return depth > 0
+		case nodeStart && (c == '\'' || c == '"'):
+			end := quotedScalarEnd(s, i)
+			if end < 0 { // continued on the next line
+				return depth > 0
+			}
+			i = end - 1
+		case nodeStart && c == '!': // the tag is followed by its node
+			for i+1 < len(s) && !isSpace(s[i+1]) && s[i+1] != '[' && s[i+1] != '{' {
+				i++
+			}
+			continue
+		case (nodeStart || depth > 0) && (c == '[' || c == '{'):
+			depth++
+			nodeStart = true
+			continue
+		case depth > 0 && (c == ']' || c == '}'):
+			depth--
+		case depth > 0 && c == ',',
+			c == ':' && separated(s, i+1),
+			nodeStart && c == '-' && separated(s, i+1):
+			nodeStart = true
+			continue
+		}
+		nodeStart = false
+	}
+	return depth > 0
+}
+
+// expandShortFormNodes rewrites the tagged nodes in the flow content s. If the node of the last tag is a block on
+// the following lines, the content before the tag and its index are returned with the long form key and the rest
+// of the line (e.g., a comment), otherwise function is "".
+func expandShortFormNodes(s string) (prefix string, tagIndex int, function, rest string) {
+	var out strings.Builder
+	depth := 0        // of flow collections
+	nodeStart := true // a node (e.g., a tag or quoted scalar) may start here
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case isSpace(c):
+			out.WriteByte(c)
+			i++
+			continue
+		case commentStart(s, i): // the comment ends with the line
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				out.WriteString(s[i:])
+				return out.String(), 0, "", ""
+			}
+			out.WriteString(s[i : i+end])
+			i += end
+			continue
+		case nodeStart && (c == '\'' || c == '"'):
+			end := quotedScalarEnd(s, i)
+			if end < 0 { // continued on the next line
+				out.WriteString(s[i:])
+				return out.String(), 0, "", ""
+			}
+			out.WriteString(s[i:end])
+			i = end
+			nodeStart = false
+			continue
+		case nodeStart && c == '-' && separated(s, i+1):
+			out.WriteByte(c)
+			i++
+			continue
+		case nodeStart && c == '!':
+			longForm, node, end := expandShortFormTag(s, i, depth)
+			if longForm == "" || end < 0 {
+				break // a tag we do not know or continued on the next line, leave it
+			}
+			if node == "" { // the node is a block on the following lines
+				return out.String(), i, longForm, s[end:]
+			}
+			out.WriteString(node)
+			i = end
+			nodeStart = false
+			continue
+		case (nodeStart || depth > 0) && (c == '[' || c == '{'):
+			depth++
+			out.WriteByte(c)
+			i++
+			nodeStart = true
+			continue
+		case depth > 0 && (c == ']' || c == '}'):
+			depth--
+		case depth > 0 && c == ',':
+			out.WriteByte(c)
+			i++
+			nodeStart = true
+			continue
+		case c == ':' && separated(s, i+1):
+			out.WriteByte(c)
+			i++
+			nodeStart = true
+			continue
+		}
+		out.WriteByte(c)
+		i++
+		nodeStart = false
+	}
+	return out.String(), 0, "", ""
+}
+
+// expandShortFormTag returns the long form key of the tag at index i of s, the long form of the tagged node and the
+// index of its end. If the node is a block on the following lines, node is "" and end is the rest of the line.
+func expandShortFormTag(s string, i, depth int) (longForm, node string, end int) {
+	nameEnd := i + 1
+	for nameEnd < len(s) && isLetter(s[nameEnd]) {
+		nameEnd++
+	}
+	longForm, isFunction := shortFormFunctions[s[i+1:nameEnd]]
+	if !isFunction || (nameEnd < len(s) && !isSpace(s[nameEnd]) && s[nameEnd] != '[') {
+		return "", "", -1 // a standard tag (e.g., !!str) or one we do not know
+	}
+	nodeIndex := nameEnd
+	for nodeIndex < len(s) && isSpace(s[nodeIndex]) {
+		nodeIndex++
+	}
+	if depth == 0 && blockNodeRegex.MatchString(s[nodeIndex:]) {
+		return longForm, "", nodeIndex
+	}
+	node, end = shortFormNode(s, nodeIndex, depth, longForm)
+	if end < 0 {
+		return longForm, "", -1
+	}
+	return longForm, `{"` + longForm + `": ` + node + `}`, end
+}
+
+// shortFormNode returns the long form of the node of a tag starting at index i of s and the index of its end,
+// or -1 if the node does not end on the line
+func shortFormNode(s string, i, depth int, longForm string) (node string, end int) {
+	switch {
+	case i < len(s) && (s[i] == '[' || s[i] == '{'):
+		end = flowCollectionEnd(s, i)
+		if end < 0 {
+			return "", -1
+		}
+		node, _, _, _ = expandShortFormNodes(s[i:end])
+		return node, end
+	case i < len(s) && (s[i] == '\'' || s[i] == '"'):
+		end = quotedScalarEnd(s, i)
+		if end < 0 {
+			return "", -1
+		}
+		node = s[i:end]
+		if longForm == "Fn::GetAtt" {
+			node = getAttNode(unquoteScalar(node))
+		}
+		return node, end
+	}
+
+	// a plain scalar ends with the flow collection, a comment or the line
+	for end = i; end < len(s); end++ {
+		if (depth > 0 && strings.IndexByte(",]}", s[end]) >= 0) || commentStart(s, end) || s[end] == '\n' {
+			break
+		}
+	}
+	value := strings.TrimRight(s[i:end], " ")
+	end = i + len(value)
+	if longForm == "Fn::GetAtt" {
+		return getAttNode(value), end
+	}
+	return strconv.Quote(value), end
+}
+
+// getAttNode returns the long form of the value of !GetAtt Resource.Attribute, the attribute may have dots
+func getAttNode(value string) string {
+	parts := strings.SplitN(value, ".", 2)
+	if len(parts) < 2 {
+		return strconv.Quote(value)
+	}
+	return "[" + strconv.Quote(parts[0]) + ", " + strconv.Quote(parts[1]) + "]"
+}
+
+// flowCollectionEnd returns the index after the flow collection starting at index i of s, or -1 if it does not end
+func flowCollectionEnd(s string, i int) int {
+	depth := 0
+	for j := i; j < len(s); j++ {
+		switch s[j] {
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+			if depth == 0 {
+				return j + 1
+			}
+		case '#':
+			if isSpace(s[j-1]) { // the comment ends with the line
+				end := strings.IndexByte(s[j:], '\n')
+				if end < 0 {
+					return -1
+				}
+				j += end
+			}
+		case '\'', '"':
+			if strings.IndexByte("[{,: \n", s[j-1]) >= 0 {
+				end := quotedScalarEnd(s, j)
+				if end < 0 {
+					return -1
+				}
+				j = end - 1
+			}
+		}
+	}
+	return -1
+}
+
+// quotedScalarEnd returns the index after the quoted scalar starting at index i of s, or -1 if it does not end
+func quotedScalarEnd(s string, i int) int {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch {
+		case quote == '"' && s[j] == '\\':
+			j++ // escaped
+		case s[j] == quote && quote == '\'' && j+1 < len(s) && s[j+1] == '\'':
+			j++ // '' is an escaped '
+		case s[j] == quote:
+			return j + 1
+		}
+	}
+	return -1
+}
+
+func unquoteScalar(scalar string) string {
+	if strings.HasPrefix(scalar, "'") {
+		return strings.ReplaceAll(strings.Trim(scalar, "'"), "''", "'")
+	}
+	if unquoted, err := strconv.Unquote(scalar); err == nil {
+		return unquoted
+	}
+	return strings.Trim(scalar, `"`)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isSpace returns whether c separates the nodes of a flow collection, which may be on several lines
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n'
+}
+
+// separated returns whether index i of s is the end of the line or a space, e.g., after "key:" or "-"
+func separated(s string, i int) bool {
+	return i == len(s) || isSpace(s[i])
+}
+
+// commentStart returns whether a comment starts at index i of s
+func commentStart(s string, i int) bool {
+	return s[i] == '#' && (i == 0 || isSpace(s[i-1]))
+}
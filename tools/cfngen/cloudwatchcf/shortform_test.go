@@ -0,0 +1,101 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestShortFormIntrinsics(t *testing.T) {
+	shortForm, err := readYaml("./testdata/shortform.yml")
+	require.NoError(t, err)
+	longForm, err := readYaml("./testdata/longform.yml")
+	require.NoError(t, err)
+	for _, section := range []string{"Conditions", "Resources"} {
+		require.Equal(t, longForm.(map[interface{}]interface{})[section], shortForm.(map[interface{}]interface{})[section])
+	}
+
+	shortFormAlarms, shortFormCf, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/shortform.yml")
+	require.NoError(t, err)
+	require.NotEmpty(t, shortFormAlarms)
+	_, longFormCf, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/longform.yml")
+	require.NoError(t, err)
+	require.Equal(t, string(longFormCf), string(shortFormCf))
+
+	shortFormCf, err = GenerateMetrics("./testdata/shortform.yml")
+	require.NoError(t, err)
+	longFormCf, err = GenerateMetrics("./testdata/longform.yml")
+	require.NoError(t, err)
+	require.Equal(t, string(longFormCf), string(shortFormCf))
+
+	// names are resolved at deploy time rather than taken literally
+	for _, alarm := range shortFormAlarms {
+		require.NotContains(t, alarm.Properties.AlarmName, "QueueNameParameter")
+		require.NotContains(t, alarm.Properties.AlarmName, "AWS::StackName")
+	}
+}
+
+func TestExpandShortFormIntrinsics(t *testing.T) {
+	for shortForm, longForm := range map[string]string{
+		`Name: !Ref Queue`:                           `Name: {"Ref": "Queue"}`,
+		`Name: !Ref 'AWS::Region' # comment`:         `Name: {"Ref": 'AWS::Region'} # comment`,
+		`Arn: !GetAtt Table.StreamArn`:               `Arn: {"Fn::GetAtt": ["Table", "StreamArn"]}`,
+		`Host: !GetAtt DB.Endpoint.Address`:          `Host: {"Fn::GetAtt": ["DB", "Endpoint.Address"]}`,
+		`- !Sub arn:${AWS::Partition}:s3:::x/*`:      `- {"Fn::Sub": "arn:${AWS::Partition}:s3:::x/*"}`,
+		`On: !If [Enabled, !Ref A, !Ref B]`:          `On: {"Fn::If": [Enabled, {"Ref": "A"}, {"Ref": "B"}]}`,
+		`Note: "!Ref is quoted"`:                     `Note: "!Ref is quoted"`,
+		`Note: it's !Ref in a plain scalar`:          `Note: it's !Ref in a plain scalar`,
+		`Value: !!str 123`:                           `Value: !!str 123`,
+		`Name: !Sub 'multi` + "\n" + `  line'`:       `Name: !Sub 'multi` + "\n" + `  line'`,
+		"Layers: !If\n  - Attach\n  - !Ref Arns":     "Layers:\n  Fn::If:\n    - Attach\n    - {\"Ref\": \"Arns\"}",
+		"List:\n- !Join\n  - ','\n  - [a, b]":        "List:\n- Fn::Join:\n    - ','\n    - [a, b]",
+		"Name: !Ref Param\r\nArn: !GetAtt A.Arn\r\n": "Name: {\"Ref\": \"Param\"}\nArn: {\"Fn::GetAtt\": [\"A\", \"Arn\"]}\n",
+		"A: [ !Ref B,\n    !Ref C ]\nD: !Ref E":      "A: [ {\"Ref\": \"B\"},\n    {\"Ref\": \"C\"} ]\nD: {\"Ref\": \"E\"}",
+		"On: !If [Enabled, # on\n  !Ref A, !Ref B]":  "On: {\"Fn::If\": [Enabled, # on\n  {\"Ref\": \"A\"}, {\"Ref\": \"B\"}]}",
+		"Code: |\n  [open\nName: !Ref Queue":         "Code: |\n  [open\nName: {\"Ref\": \"Queue\"}",
+	} {
+		expanded, err := ioutil.ReadAll(newShortFormReader(strings.NewReader(shortForm)))
+		require.NoError(t, err)
+		require.Equal(t, longForm, string(expanded), shortForm)
+
+		// the lines are rewritten however the decoder reads them
+		expanded, err = ioutil.ReadAll(iotest.OneByteReader(newShortFormReader(iotest.HalfReader(strings.NewReader(shortForm)))))
+		require.NoError(t, err)
+		require.Equal(t, longForm, string(expanded), shortForm)
+	}
+}
+
+func TestShortFormLineBreaks(t *testing.T) {
+	for _, shortForm := range []string{
+		"Queue: !Ref Param\r\nList:\r\n  - !Ref Param\r\n",
+		"Queue:\n  !Ref Param\nList: [ !Ref Param,\n    # the queue\n  ]\n",
+	} {
+		var template map[string]interface{}
+		require.NoError(t, yaml.NewDecoder(newShortFormReader(strings.NewReader(shortForm))).Decode(&template), shortForm)
+		require.Equal(t, map[string]interface{}{
+			"Queue": map[interface{}]interface{}{"Ref": "Param"},
+			"List":  []interface{}{map[interface{}]interface{}{"Ref": "Param"}},
+		}, template, shortForm)
+	}
+}
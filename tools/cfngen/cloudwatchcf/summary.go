@@ -0,0 +1,98 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Summary is the coverage of generated alarms, so gaps are noticed (e.g., CI can fail if a resource type has no alarms)
+type Summary struct {
+	// Alarms counts the alarms by the type of the monitored resource and then the metric
+	Alarms map[string]map[string]int
+	// Unalarmed are the logical ids of the resources of supported types that have no alarms (e.g., excluded), by type
+	Unalarmed map[string][]string
+}
+
+// SummarizeAlarms summarizes the alarms generated for the CF in the cfDirs
+func SummarizeAlarms(alarms []*Alarm, cfDirs ...string) (*Summary, error) {
+	template, err := readAlarmTemplate(cfDirs...)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &Summary{
+		Alarms:    make(map[string]map[string]int),
+		Unalarmed: make(map[string][]string),
+	}
+	alarmed := make(map[string]struct{})
+	for _, alarm := range alarms {
+		alarmed[alarm.LogicalID] = struct{}{}
+		resourceType, _ := template.resources[alarm.LogicalID]["Type"].(string)
+		if summary.Alarms[resourceType] == nil {
+			summary.Alarms[resourceType] = make(map[string]int)
+		}
+		summary.Alarms[resourceType][alarm.Properties.MetricName]++
+	}
+	for logicalID, resource := range template.resources {
+		resourceType, _ := resource["Type"].(string)
+		if _, isSupported := knownMetrics[resourceType]; !isSupported {
+			continue
+		}
+		if _, isAlarmed := alarmed[logicalID]; !isAlarmed {
+			summary.Unalarmed[resourceType] = append(summary.Unalarmed[resourceType], logicalID)
+		}
+	}
+	for _, logicalIDs := range summary.Unalarmed {
+		sort.Strings(logicalIDs)
+	}
+	return summary, nil
+}
+
+// Count returns the number of alarms for the resource type
+func (summary *Summary) Count(resourceType string) (count int) {
+	for _, metricCount := range summary.Alarms[resourceType] {
+		count += metricCount
+	}
+	return count
+}
+
+// String reports the summary one resource type per line, e.g.,
+// "AWS::DynamoDB::Table: 0 alarms, no alarms for AlertsTable, EventsTable"
+func (summary *Summary) String() string {
+	resourceTypes := make(map[string]struct{})
+	for resourceType := range summary.Alarms {
+		resourceTypes[resourceType] = struct{}{}
+	}
+	for resourceType := range summary.Unalarmed {
+		resourceTypes[resourceType] = struct{}{}
+	}
+	lines := make([]string, 0, len(resourceTypes))
+	for resourceType := range resourceTypes {
+		line := fmt.Sprintf("%s: %d alarms", resourceType, summary.Count(resourceType))
+		if unalarmed := summary.Unalarmed[resourceType]; len(unalarmed) > 0 {
+			line += ", no alarms for " + strings.Join(unalarmed, ", ")
+		}
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
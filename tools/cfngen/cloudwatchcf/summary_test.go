@@ -0,0 +1,55 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeAlarms(t *testing.T) {
+	cfDirs := []string{"./testdata/lambda.yml", "./testdata/glue.yml", "./testdata/sns.yml"}
+	alarms, _, err := GenerateAlarms("my-sns-topic-arn", nil, cfDirs...)
+	require.NoError(t, err)
+	summary, err := SummarizeAlarms(alarms, cfDirs...)
+	require.NoError(t, err)
+
+	require.Equal(t, 14, summary.Count("AWS::Serverless::Function")+summary.Count("AWS::Lambda::Function"))
+	require.Equal(t, map[string]int{
+		"Errors":              1,
+		"Throttles":           1,
+		"Duration":            1,
+		"raw-function-errors": 1,
+		"raw-function-warns":  1,
+		"raw-function-panics": 1,
+		"raw-function-memory": 1,
+	}, summary.Alarms["AWS::Lambda::Function"])
+	require.Equal(t, 2, summary.Count("AWS::Glue::Job"))
+	require.Equal(t, 4, summary.Count("AWS::SNS::Topic"))
+	require.Zero(t, summary.Count("AWS::DynamoDB::Table"))
+
+	// the untagged job is found but has no alarms
+	require.Equal(t, map[string][]string{"AWS::Glue::Job": {"ScratchJob"}}, summary.Unalarmed)
+
+	require.Equal(t, `AWS::Glue::Job: 2 alarms, no alarms for ScratchJob
+AWS::Lambda::Function: 7 alarms
+AWS::SNS::Topic: 4 alarms
+AWS::Serverless::Function: 7 alarms`, summary.String())
+}
@@ -0,0 +1,47 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"sort"
+)
+
+// Tag is a CF resource tag, see:
+// https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-resource-tags.html
+type Tag struct {
+	Key   string
+	Value string
+}
+
+// Tags configures tags applied to every generated resource that supports them (alarms and composite alarms).
+// Metric filters, anomaly detectors, query definitions and dashboards cannot be tagged in CF and are left as is.
+func (config *Config) Tags(tags map[string]string) *Config {
+	config.tags = tags
+	return config
+}
+
+// cfTags returns the tags sorted by key so the generated CF is deterministic
+func cfTags(tags map[string]string) (cfTags []Tag) {
+	for key, value := range tags {
+		cfTags = append(cfTags, Tag{Key: key, Value: value})
+	}
+	sort.Slice(cfTags, func(i, j int) bool {
+		return cfTags[i].Key < cfTags[j].Key
+	})
+	return cfTags
+}
@@ -0,0 +1,69 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags(t *testing.T) {
+	config := NewConfig("my-sns-topic-arn", nil).Tags(map[string]string{
+		"team":        "platform",
+		"cost-center": "1234",
+	})
+	expectedTags := []Tag{ // sorted by key
+		{Key: "cost-center", Value: "1234"},
+		{Key: "team", Value: "platform"},
+	}
+
+	alarms, cf, err := GenerateAlarmsWithConfig(config, "./testdata/firehose.yml")
+	require.NoError(t, err)
+	require.NotEmpty(t, alarms)
+	for _, alarm := range alarms {
+		require.Equal(t, expectedTags, alarm.Properties.Tags)
+	}
+	var template struct {
+		Resources map[string]*Alarm
+	}
+	require.NoError(t, json.Unmarshal(cf, &template))
+	for _, resource := range template.Resources {
+		if resource.Type == "AWS::CloudWatch::Alarm" {
+			require.Equal(t, expectedTags, resource.Properties.Tags)
+		}
+	}
+
+	compositeAlarms, _, err := GenerateCompositeAlarms(alarms)
+	require.NoError(t, err)
+	require.NotEmpty(t, compositeAlarms)
+	require.Equal(t, expectedTags, compositeAlarms[0].Properties.Tags)
+
+	require.Contains(t, string(TerraformAlarms(alarms)), `tags                = { "cost-center" = "1234", "team" = "platform" }`)
+
+	// dashboards do not support tags
+	cf, err = GenerateDashboardWithConfig(config, "eu-west-1", "TestDashboard", "./testdata/cf.yml")
+	require.NoError(t, err)
+	require.NotContains(t, string(cf), "Tags")
+	require.NotContains(t, string(cf), "platform")
+
+	// none by default
+	_, cf, err = GenerateAlarms("my-sns-topic-arn", nil, "./testdata/firehose.yml")
+	require.NoError(t, err)
+	require.NotContains(t, string(cf), `"Tags"`)
+}
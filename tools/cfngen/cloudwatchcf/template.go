@@ -0,0 +1,102 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+// Template holds the generated resources in memory so they can be inspected or modified (e.g., a threshold changed)
+// before being serialized to CF with Marshal
+type Template struct {
+	Description      string
+	MetricFilters    []*MetricFilter
+	Alarms           []*Alarm                    // sorted by name
+	QueryDefinitions map[string]*QueryDefinition // keyed by resource name
+	Warnings         []Warning                   // resources of supported types without alarms, not serialized
+}
+
+// BuildAlarms returns the alarms (and any configured queries) for the CF in the cfDirs without serializing them,
+// with Warnings for the resources that could not be alarmed
+func BuildAlarms(config *Config, cfDirs ...string) (*Template, error) {
+	alarms, warnings, err := generateSortedAlarms(config, cfDirs...)
+	if err != nil {
+		return nil, err
+	}
+	queryDefinitions, err := generateQueryDefinitions(config, cfDirs...)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{
+		Description:      "Panther Alarms",
+		Alarms:           alarms,
+		QueryDefinitions: queryDefinitions,
+		Warnings:         warnings,
+	}, nil
+}
+
+// BuildMetrics returns the metric filters for the CF in the cfDirs without serializing them
+func BuildMetrics(config *Config, cfDirs ...string) (*Template, error) {
+	metricFilters, err := generateAllMetricFilters(config, cfDirs...)
+	if err != nil {
+		return nil, err
+	}
+	return &Template{
+		Description:   "Panther Metrics",
+		MetricFilters: metricFilters,
+	}, nil
+}
+
+// BuildMonitoring returns the metric filters, alarms and any configured queries for the CF in the cfDirs without
+// serializing them
+func BuildMonitoring(config *Config, cfDirs ...string) (*Template, error) {
+	metrics, err := BuildMetrics(config, cfDirs...)
+	if err != nil {
+		return nil, err
+	}
+	alarms, err := BuildAlarms(config, cfDirs...)
+	if err != nil {
+		return nil, err
+	}
+	alarms.Description = "Panther Monitoring"
+	alarms.MetricFilters = metrics.MetricFilters
+	return alarms, nil
+}
+
+// Resources returns the CF resources of the template keyed by resource name, with the dependencies of the alarms set
+func (template *Template) Resources() map[string]interface{} {
+	resources := metricFilterResources(template.MetricFilters)
+	for resourceName, resource := range alarmResources(template.Alarms) {
+		resources[resourceName] = resource
+	}
+	for resourceName, queryDefinition := range template.QueryDefinitions {
+		resources[resourceName] = queryDefinition
+	}
+	addDependencies(resources)
+	return resources
+}
+
+// Marshal serializes the template to CF in the format
+func (template *Template) Marshal(format OutputFormat) ([]byte, error) {
+	// generate CF using cfngen
+	cfTemplate := cfngen.NewTemplate(template.Description, nil, template.Resources(), nil)
+	if format == YAMLFormat {
+		return cfTemplate.CloudFormationYAML()
+	}
+	return cfTemplate.CloudFormation()
+}
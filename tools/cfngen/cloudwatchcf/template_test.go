@@ -0,0 +1,66 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildAlarmsMarshal(t *testing.T) {
+	template, err := BuildAlarms(NewConfig("my-sns-topic-arn", nil), "./testdata/firehose.yml")
+	require.NoError(t, err)
+	require.NotEmpty(t, template.Alarms)
+
+	// unchanged, the same CF as GenerateAlarms
+	_, expectedCf, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/firehose.yml")
+	require.NoError(t, err)
+	cf, err := template.Marshal(JSONFormat)
+	require.NoError(t, err)
+	require.Equal(t, expectedCf, cf)
+
+	alarm := template.Alarms[0]
+	threshold := float32(1234)
+	alarm.Properties.Threshold = &threshold
+	cf, err = template.Marshal(JSONFormat)
+	require.NoError(t, err)
+
+	var cfTemplate struct {
+		Description string
+		Resources   map[string]*Alarm
+	}
+	require.NoError(t, json.Unmarshal(cf, &cfTemplate))
+	require.Equal(t, "Panther Alarms", cfTemplate.Description)
+	require.Len(t, cfTemplate.Resources, len(template.Alarms))
+	marshaled := cfTemplate.Resources[cfResourceName(alarm.Properties.AlarmName)]
+	require.NotNil(t, marshaled)
+	require.Equal(t, threshold, *marshaled.Properties.Threshold)
+}
+
+func TestBuildMetricsMarshal(t *testing.T) {
+	template, err := BuildMetrics(NewConfig("", nil), "./testdata/cf.yml")
+	require.NoError(t, err)
+	require.NotEmpty(t, template.MetricFilters)
+
+	cf, err := template.Marshal(YAMLFormat)
+	require.NoError(t, err)
+	expectedCf, err := GenerateMetricsWithConfig(NewConfig("", nil).Format(YAMLFormat), "./testdata/cf.yml")
+	require.NoError(t, err)
+	require.Equal(t, expectedCf, cf)
+}
@@ -0,0 +1,116 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// cfTemplate is an input CF template
+type cfTemplate struct {
+	path      string // "stdin" for StdinPath
+	resources cfResources
+}
+
+// eachTemplate applies the templateFunc to the CF templates in the cfDirs, a cfDir of StdinPath reads a single template
+// from stdin. The templates are read one at a time, so only the template being generated is in memory rather than
+// the whole deployment, and what the templates need of each other is collected by the templateFunc as it goes (e.g.,
+// see addConsumedQueueNames). A template in more than one cfDir (e.g., a file and its dir) is read once. The generated
+// resources of the templates are merged, so it is an error for templates to define the same resource.
+func eachTemplate(cfDirs []string, templateFunc func(template *cfTemplate) error) error {
+	conflicts := newTemplateConflicts()
+	visit := func(template *cfTemplate) error {
+		if err := conflicts.check(template); err != nil {
+			return err
+		}
+		return templateFunc(template)
+	}
+
+	read := make(map[string]struct{})
+	for _, cfDir := range cfDirs {
+		if cfDir == StdinPath {
+			resources, err := decodeYamlResources(os.Stdin)
+			if err != nil {
+				return errors.Wrap(err, "stdin")
+			}
+			if err = visit(&cfTemplate{path: "stdin", resources: resources}); err != nil {
+				return err
+			}
+			continue
+		}
+		var visitErr error // returned as is, the errors of the templateFunc name their templates
+		err := walkYamlFiles(cfDir, func(path string) error {
+			if _, isRead := read[filepath.Clean(path)]; isRead {
+				return nil
+			}
+			read[filepath.Clean(path)] = struct{}{}
+			resources, err := readYamlResources(path)
+			if err != nil {
+				return err
+			}
+			visitErr = visit(&cfTemplate{path: path, resources: resources})
+			return visitErr
+		})
+		if visitErr != nil {
+			return visitErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateConflicts are the resources of the templates checked so far, a resource is identified by its type and
+// literal name. Logical ids are only unique within a template and resources named by CF are distinct, so these do
+// not conflict.
+type templateConflicts struct {
+	definedBy map[string]string // type and name -> template and logical id
+}
+
+func newTemplateConflicts() *templateConflicts {
+	return &templateConflicts{definedBy: make(map[string]string)}
+}
+
+// check returns an error for the resources of the template defined by the templates checked before it
+func (conflicts *templateConflicts) check(template *cfTemplate) error {
+	var found []string
+	template.resources.walk(func(logicalID, resourceType string, resource map[interface{}]interface{}) {
+		name := getLiteralResourceName(resource)
+		if name == "" {
+			return
+		}
+		key := resourceType + " " + name
+		definition := template.path + " (" + logicalID + ")"
+		if otherDefinition, isDefined := conflicts.definedBy[key]; isDefined {
+			found = append(found, key+" is defined in "+otherDefinition+" and "+definition)
+			return
+		}
+		conflicts.definedBy[key] = definition
+	})
+	if len(found) > 0 {
+		sort.Strings(found)
+		return errors.New("templates define the same resources: " + strings.Join(found, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeTemplates(t *testing.T) {
+	const (
+		coreTemplate = "./testdata/merge/core.yml"
+		webTemplate  = "./testdata/merge/web.yml"
+	)
+	resourceNames := func(cf []byte) (names []string) {
+		var template struct {
+			Resources map[string]interface{}
+		}
+		require.NoError(t, json.Unmarshal(cf, &template))
+		for name := range template.Resources {
+			names = append(names, name)
+		}
+		return names
+	}
+
+	// one template of metric filters for both, the functions have the same logical id but are distinct
+	cf, err := GenerateMetrics(coreTemplate, webTemplate)
+	require.NoError(t, err)
+	requireValidCloudFormation(t, cf)
+	coreCf, err := GenerateMetrics(coreTemplate)
+	require.NoError(t, err)
+	webCf, err := GenerateMetrics(webTemplate)
+	require.NoError(t, err)
+	require.ElementsMatch(t, append(resourceNames(coreCf), resourceNames(webCf)...), resourceNames(cf))
+
+	// the same for alarms, the alarm names are unique across the templates
+	alarms, cf, err := GenerateAlarms("my-sns-topic-arn", nil, coreTemplate, webTemplate)
+	require.NoError(t, err)
+	requireValidCloudFormation(t, cf)
+	require.NoError(t, ValidateAlarms(alarms, coreTemplate, webTemplate))
+	_, coreCf, err = GenerateAlarms("my-sns-topic-arn", nil, coreTemplate)
+	require.NoError(t, err)
+	_, webCf, err = GenerateAlarms("my-sns-topic-arn", nil, webTemplate)
+	require.NoError(t, err)
+	// the queue of the core stack is consumed by the web stack, which alarms on its age naming the consumer
+	const queueAgeAlarm = "PantherAlarmSQSTooOldpanthercorequeueQueueApproximateAgeOfOldestMessageMaximum"
+	require.Contains(t, resourceNames(coreCf), queueAgeAlarm)
+	require.NotContains(t, resourceNames(cf), queueAgeAlarm)
+	require.Contains(t, resourceNames(cf),
+		"PantherAlarmSQSConsumerLagpanthercorequeueQueueEventSourceApproximateAgeOfOldestMessageMaximum")
+	var separateNames []string
+	for _, name := range append(resourceNames(coreCf), resourceNames(webCf)...) {
+		if name != queueAgeAlarm {
+			separateNames = append(separateNames, name)
+		}
+	}
+	require.ElementsMatch(t, separateNames, resourceNames(cf))
+
+	// a template in more than one of the cfDirs is read once
+	dirAlarms, dirCf, err := GenerateAlarms("my-sns-topic-arn", nil, "./testdata/merge", coreTemplate)
+	require.NoError(t, err)
+	require.Equal(t, cf, dirCf)
+	require.Len(t, dirAlarms, len(alarms))
+}
+
+func TestMergeTemplatesConflict(t *testing.T) {
+	_, err := GenerateMetrics("./testdata/merge", "./testdata/conflict.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "AWS::Lambda::Function panther-core is defined in testdata/merge/core.yml (Function) "+
+		"and ./testdata/conflict.yml (CoreFunction)")
+
+	_, _, err = GenerateAlarms("my-sns-topic-arn", nil, "./testdata/merge", "./testdata/conflict.yml")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "AWS::Lambda::Function panther-core is defined in")
+}
+
+func TestEachTemplate(t *testing.T) {
+	var visited []string
+	err := eachTemplate([]string{"./testdata/merge", "./testdata/merge/core.yml"}, func(template *cfTemplate) error {
+		require.NotEmpty(t, template.resources)
+		visited = append(visited, template.path)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"testdata/merge/core.yml", "testdata/merge/web.yml"}, visited)
+
+	// a template defining the resources of an earlier one is not generated
+	visited = nil
+	err = eachTemplate([]string{"./testdata/merge", "./testdata/conflict.yml"}, func(template *cfTemplate) error {
+		visited = append(visited, template.path)
+		return nil
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "templates define the same resources")
+	require.Equal(t, []string{"testdata/merge/core.yml", "testdata/merge/web.yml"}, visited)
+}
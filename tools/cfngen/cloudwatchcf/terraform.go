@@ -0,0 +1,312 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+// GenerateAlarmsTerraform is GenerateAlarmsWithConfig emitting Terraform aws_cloudwatch_metric_alarm resources
+// rather than CF. Refs and GetAtts become Terraform variables, which are declared in the output and must be set
+// by the caller (e.g., Ref to Function is var.Function, GetAtt of Queue.Arn is var.Queue_Arn). Other CF intrinsics
+// cannot be resolved and are left as literal JSON with a warning.
+func GenerateAlarmsTerraform(config *Config, cfDirs ...string) (alarms []*Alarm, tf []byte, err error) {
+	alarms, _, err = generateSortedAlarms(config, cfDirs...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return alarms, TerraformAlarms(alarms), nil
+}
+
+// TerraformAlarms returns the Terraform HCL for the alarms
+func TerraformAlarms(alarms []*Alarm) []byte {
+	tf := &terraformWriter{variables: make(map[string]struct{})}
+	var resources bytes.Buffer
+	for _, alarm := range alarms {
+		resources.WriteString("\n")
+		tf.writeAlarm(&resources, alarm)
+		if alarm.maintenanceAlarm != nil {
+			resources.WriteString("\n")
+			tf.writeCompositeAlarm(&resources, alarm.maintenanceAlarm)
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("# Panther Alarms\n")
+	variables := make([]string, 0, len(tf.variables))
+	for variable := range tf.variables {
+		variables = append(variables, variable)
+	}
+	sort.Strings(variables)
+	for _, variable := range variables {
+		fmt.Fprintf(&out, "\nvariable %q {\n  type = string\n}\n", variable)
+	}
+	out.Write(resources.Bytes())
+	return out.Bytes()
+}
+
+type terraformWriter struct {
+	variables map[string]struct{} // declared for CF Refs and GetAtts
+}
+
+// terraformAttribute is a line of a block, name = value
+type terraformAttribute struct {
+	name  string
+	value string
+}
+
+func (tf *terraformWriter) writeAlarm(out *bytes.Buffer, alarm *Alarm) {
+	alarm.renderMetricMath()
+	if alarm.AnomalyBand > 0 && alarm.metricMath == nil {
+		// CloudWatch creates the anomaly detection model for the metric with the alarm, no detector resource is needed
+		alarm.anomalyDetection()
+	}
+	props := &alarm.Properties
+
+	var attributes []terraformAttribute
+	add := func(name, value string) {
+		attributes = append(attributes, terraformAttribute{name: name, value: value})
+	}
+	addString := func(name, value string) {
+		if value != "" {
+			add(name, terraformString(value))
+		}
+	}
+	addList := func(name string, values []interface{}) {
+		if len(values) > 0 {
+			add(name, tf.list(values))
+		}
+	}
+	addString("alarm_name", props.AlarmName)
+	addString("alarm_description", props.AlarmDescription)
+	if props.ActionsEnabled != nil {
+		add("actions_enabled", strconv.FormatBool(*props.ActionsEnabled))
+	}
+	addList("alarm_actions", props.AlarmActions)
+	addList("ok_actions", props.OKActions)
+	addList("insufficient_data_actions", props.InsufficientDataActions)
+	addString("treat_missing_data", props.TreatMissingData)
+	addString("namespace", props.Namespace)
+	addString("metric_name", props.MetricName)
+	if len(props.Dimensions) > 0 {
+		add("dimensions", tf.dimensions(props.Dimensions))
+	}
+	addString("comparison_operator", props.ComparisonOperator)
+	add("evaluation_periods", strconv.Itoa(props.EvaluationPeriods))
+	if props.DatapointsToAlarm > 0 {
+		add("datapoints_to_alarm", strconv.Itoa(props.DatapointsToAlarm))
+	}
+	if props.Period > 0 {
+		add("period", strconv.Itoa(props.Period))
+	}
+	if props.Threshold != nil {
+		add("threshold", strconv.FormatFloat(float64(*props.Threshold), 'g', -1, 32))
+	}
+	addString("threshold_metric_id", props.ThresholdMetricID)
+	addString("unit", props.Unit)
+	addString("statistic", props.Statistic)
+	addString("extended_statistic", props.ExtendedStatistic)
+	if len(props.Tags) > 0 {
+		add("tags", terraformTags(props.Tags))
+	}
+
+	fmt.Fprintf(out, "resource \"aws_cloudwatch_metric_alarm\" %q {\n", cfResourceName(props.AlarmName))
+	writeTerraformAttributes(out, "  ", attributes)
+	for _, query := range props.Metrics {
+		tf.writeMetricQuery(out, &query)
+	}
+	out.WriteString("}\n")
+}
+
+// writeCompositeAlarm writes the companion composite alarm of an alarm suppressed during maintenance
+func (tf *terraformWriter) writeCompositeAlarm(out *bytes.Buffer, compositeAlarm *CompositeAlarm) {
+	props := &compositeAlarm.Properties
+
+	var attributes []terraformAttribute
+	add := func(name, value string) {
+		attributes = append(attributes, terraformAttribute{name: name, value: value})
+	}
+	addList := func(name string, values []interface{}) {
+		if len(values) > 0 {
+			add(name, tf.list(values))
+		}
+	}
+	add("alarm_name", terraformString(props.AlarmName))
+	if props.AlarmDescription != "" {
+		add("alarm_description", terraformString(props.AlarmDescription))
+	}
+	if props.ActionsEnabled != nil {
+		add("actions_enabled", strconv.FormatBool(*props.ActionsEnabled))
+	}
+	addList("alarm_actions", props.AlarmActions)
+	addList("ok_actions", props.OKActions)
+	addList("insufficient_data_actions", props.InsufficientDataActions)
+	add("alarm_rule", terraformString(props.AlarmRule))
+	if len(props.Tags) > 0 {
+		add("tags", terraformTags(props.Tags))
+	}
+
+	fmt.Fprintf(out, "resource \"aws_cloudwatch_composite_alarm\" %q {\n", cfResourceName(props.AlarmName))
+	writeTerraformAttributes(out, "  ", attributes)
+	if props.ActionsSuppressor != nil {
+		out.WriteString("\n  actions_suppressor {\n")
+		writeTerraformAttributes(out, "    ", []terraformAttribute{
+			{name: "alarm", value: tf.value(props.ActionsSuppressor)},
+			{name: "extension_period", value: strconv.Itoa(props.ActionsSuppressorExtensionPeriod)},
+			{name: "wait_period", value: strconv.Itoa(props.ActionsSuppressorWaitPeriod)},
+		})
+		out.WriteString("  }\n")
+	}
+	out.WriteString("}\n")
+}
+
+func (tf *terraformWriter) writeMetricQuery(out *bytes.Buffer, query *MetricDataQuery) {
+	attributes := []terraformAttribute{{name: "id", value: terraformString(query.ID)}}
+	if query.Expression != "" {
+		attributes = append(attributes, terraformAttribute{name: "expression", value: terraformString(query.Expression)})
+	}
+	if query.Label != "" {
+		attributes = append(attributes, terraformAttribute{name: "label", value: terraformString(query.Label)})
+	}
+	attributes = append(attributes, terraformAttribute{name: "return_data", value: strconv.FormatBool(query.ReturnData)})
+
+	out.WriteString("\n  metric_query {\n")
+	writeTerraformAttributes(out, "    ", attributes)
+	if stat := query.MetricStat; stat != nil {
+		attributes = []terraformAttribute{
+			{name: "namespace", value: terraformString(stat.Metric.Namespace)},
+			{name: "metric_name", value: terraformString(stat.Metric.MetricName)},
+		}
+		if len(stat.Metric.Dimensions) > 0 {
+			attributes = append(attributes, terraformAttribute{name: "dimensions", value: tf.dimensions(stat.Metric.Dimensions)})
+		}
+		attributes = append(attributes,
+			terraformAttribute{name: "period", value: strconv.Itoa(stat.Period)},
+			terraformAttribute{name: "stat", value: terraformString(stat.Stat)})
+		if stat.Unit != "" {
+			attributes = append(attributes, terraformAttribute{name: "unit", value: terraformString(stat.Unit)})
+		}
+		out.WriteString("\n    metric {\n")
+		writeTerraformAttributes(out, "      ", attributes)
+		out.WriteString("    }\n")
+	}
+	out.WriteString("  }\n")
+}
+
+// writeTerraformAttributes writes the attributes with the = aligned, as terraform fmt does
+func writeTerraformAttributes(out *bytes.Buffer, indent string, attributes []terraformAttribute) {
+	width := 0
+	for _, attribute := range attributes {
+		if len(attribute.name) > width {
+			width = len(attribute.name)
+		}
+	}
+	for _, attribute := range attributes {
+		fmt.Fprintf(out, "%s%-*s = %s\n", indent, width, attribute.name, attribute.value)
+	}
+}
+
+func (tf *terraformWriter) list(values []interface{}) string {
+	elements := make([]string, len(values))
+	for i, value := range values {
+		elements[i] = tf.value(value)
+	}
+	return "[" + strings.Join(elements, ", ") + "]"
+}
+
+func (tf *terraformWriter) dimensions(dimensions []MetricDimension) string {
+	elements := make([]string, len(dimensions))
+	for i, dimension := range dimensions {
+		elements[i] = dimension.Name + " = " + tf.value(dimension.Value)
+	}
+	return "{ " + strings.Join(elements, ", ") + " }"
+}
+
+func terraformTags(tags []Tag) string {
+	elements := make([]string, len(tags))
+	for i, tag := range tags {
+		elements[i] = terraformString(tag.Key) + " = " + terraformString(tag.Value)
+	}
+	return "{ " + strings.Join(elements, ", ") + " }"
+}
+
+// value maps a CF value to Terraform, Refs and GetAtts become variables
+func (tf *terraformWriter) value(value interface{}) string {
+	if variable := terraformVariable(value); variable != "" {
+		tf.variables[variable] = struct{}{}
+		return "var." + variable
+	}
+	switch val := value.(type) {
+	case string:
+		return terraformString(val)
+	case map[string]interface{}, map[interface{}]interface{}:
+		literal, err := json.Marshal(cfIntrinsic(val))
+		if err != nil {
+			literal = []byte(fmt.Sprintf("%v", val))
+		}
+		zap.L().Warn("CF intrinsic cannot be resolved in Terraform, using it as a literal",
+			zap.String("intrinsic", string(literal)))
+		return terraformString(string(literal))
+	default:
+		return terraformString(fmt.Sprintf("%v", val))
+	}
+}
+
+// terraformVariable returns the name of the variable for a CF Ref or GetAtt, or "" otherwise
+func terraformVariable(value interface{}) string {
+	switch val := value.(type) {
+	case cfngen.Ref:
+		return val.Ref
+	case *cfngen.Ref:
+		return val.Ref
+	case map[string]interface{}:
+		if len(val) != 1 {
+			return ""
+		}
+		if ref, ok := val["Ref"].(string); ok && !strings.HasPrefix(ref, "AWS::") { // pseudo parameters are not supported
+			return ref
+		}
+		switch getAtt := val["Fn::GetAtt"].(type) {
+		case []interface{}:
+			parts := make([]string, len(getAtt))
+			for i := range getAtt {
+				parts[i] = fmt.Sprintf("%v", getAtt[i])
+			}
+			return strings.ReplaceAll(strings.Join(parts, "_"), ".", "_")
+		case string:
+			return strings.ReplaceAll(getAtt, ".", "_")
+		}
+	}
+	return ""
+}
+
+// terraformString quotes the string, escaping Terraform template sequences
+func terraformString(value string) string {
+	quoted := strconv.Quote(value)
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	return strings.ReplaceAll(quoted, "%{", "%%{")
+}
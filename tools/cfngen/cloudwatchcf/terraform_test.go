@@ -0,0 +1,57 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/panther-labs/panther/tools/cfngen"
+)
+
+func TestGenerateAlarmsTerraform(t *testing.T) {
+	_, tf, err := GenerateAlarmsTerraform(NewConfig("my-sns-topic-arn", testStackOutputs()), "./testdata/cf.yml")
+	require.NoError(t, err)
+	requireGoldenFile(t, "./testdata/generated_test_alarms.tf", tf)
+}
+
+func TestGenerateAlarmsTerraformIntrinsics(t *testing.T) {
+	core, logs := observer.New(zap.WarnLevel)
+	defer zap.ReplaceGlobals(zap.New(core))()
+
+	alarm := NewAlarm("test-lambda", "PantherAlarm-LambdaErrors-test-lambda", "Lambda test-lambda is failing",
+		"my-sns-topic-arn").Metric("AWS/Lambda", "Errors", []MetricDimension{
+		{Name: "FunctionName", Value: cfngen.Ref{Ref: "Function"}},
+		{Name: "Resource", Value: map[string]interface{}{"Fn::GetAtt": []interface{}{"Function", "Arn"}}},
+		{Name: "Alias", Value: map[string]interface{}{"Fn::Sub": "${Function}:live"}},
+	}).SumCountThreshold(0, 60*5)
+	alarm.Properties.OKActions = []interface{}{map[string]interface{}{"Ref": "OpsTopic"}}
+
+	tf := string(TerraformAlarms([]*Alarm{alarm}))
+	require.Contains(t, tf, "variable \"Function\" {\n  type = string\n}\n")
+	require.Contains(t, tf, "variable \"Function_Arn\" {\n  type = string\n}\n")
+	require.Contains(t, tf, "variable \"OpsTopic\" {\n  type = string\n}\n")
+	require.Contains(t, tf,
+		`dimensions          = { FunctionName = var.Function, Resource = var.Function_Arn, Alias = "{\"Fn::Sub\":\"$${Function}:live\"}" }`)
+	require.Contains(t, tf, `ok_actions          = [var.OpsTopic]`)
+	require.Equal(t, 1, logs.FilterMessage("CF intrinsic cannot be resolved in Terraform, using it as a literal").Len())
+}
@@ -0,0 +1,637 @@
+# Panther Alarms
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmApiGatewayHighIntegationLatencypantherresourcesapiGatewayApiIntegrationLatencyMaximum" {
+  alarm_name          = "PantherAlarm-ApiGatewayHighIntegationLatency-panther-resources-api-GatewayApi-IntegrationLatency-Maximum"
+  alarm_description   = "ApiGateway panther-resources-api is experience high integration latency. See: https://docs.runpanther.io/operations/runbooks#panther-resources-api"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/ApiGateway"
+  metric_name         = "IntegrationLatency"
+  dimensions          = { Name = "panther-resources-api" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 5
+  period              = 60
+  threshold           = 1000
+  unit                = "Milliseconds"
+  statistic           = "Maximum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmApiGatewayHighLatencypantherresourcesapiGatewayApiLatencyMaximum" {
+  alarm_name          = "PantherAlarm-ApiGatewayHighLatency-panther-resources-api-GatewayApi-Latency-Maximum"
+  alarm_description   = "ApiGateway panther-resources-api is experience high latency. See: https://docs.runpanther.io/operations/runbooks#panther-resources-api"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/ApiGateway"
+  metric_name         = "Latency"
+  dimensions          = { Name = "panther-resources-api" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 5
+  period              = 60
+  threshold           = 1000
+  unit                = "Milliseconds"
+  statistic           = "Maximum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmApiGatewayServerErrorpantherresourcesapiGatewayApi5XXErrorSum" {
+  alarm_name          = "PantherAlarm-ApiGatewayServerError-panther-resources-api-GatewayApi-5XXError-Sum"
+  alarm_description   = "ApiGateway panther-resources-api is failing. See: https://docs.runpanther.io/operations/runbooks#panther-resources-api"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/ApiGateway"
+  metric_name         = "5XXError"
+  dimensions          = { Name = "panther-resources-api" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmAppSyncClientErrorpanthergraphqlapiGraphQLApi4XXErrorSum" {
+  alarm_name          = "PantherAlarm-AppSyncClientError-panther-graphql-api-GraphQLApi-4XXError-Sum"
+  alarm_description   = "AppSync panther-graphql-api has has elevated 4XX errors. See: https://docs.runpanther.io/operations/runbooks#panther-graphql-api"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/AppSync"
+  metric_name         = "4XXError"
+  dimensions          = { GraphQLAPIId = "testGraphqlId" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 20
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmAppSyncHighLatencypanthergraphqlapiGraphQLApiLatencyp90" {
+  alarm_name          = "PantherAlarm-AppSyncHighLatency-panther-graphql-api-GraphQLApi-Latency-p90"
+  alarm_description   = "AppSync panther-graphql-api is experience high latency. See: https://docs.runpanther.io/operations/runbooks#panther-graphql-api"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/AppSync"
+  metric_name         = "Latency"
+  dimensions          = { GraphQLAPIId = "testGraphqlId" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 5
+  period              = 60
+  threshold           = 1000
+  unit                = "None"
+  extended_statistic  = "p90"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmAppSyncServerErrorpanthergraphqlapiGraphQLApi5XXErrorSum" {
+  alarm_name          = "PantherAlarm-AppSyncServerError-panther-graphql-api-GraphQLApi-5XXError-Sum"
+  alarm_description   = "AppSync panther-graphql-api is failing. See: https://docs.runpanther.io/operations/runbooks#panther-graphql-api"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/AppSync"
+  metric_name         = "5XXError"
+  dimensions          = { GraphQLAPIId = "testGraphqlId" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBBatchWriteItemErrorpanthercomplianceComplianceTableSystemErrorsSum" {
+  alarm_name          = "PantherAlarm-DDBBatchWriteItemError-panther-compliance-ComplianceTable-SystemErrors-Sum"
+  alarm_description   = "DynamoDB panther-compliance is failing BatchWriteItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "SystemErrors"
+  dimensions          = { TableName = "panther-compliance", Operation = "BatchWriteItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBBatchWriteItemHighLatencypanthercomplianceComplianceTableSuccessfulRequestLatencyMaximum" {
+  alarm_name          = "PantherAlarm-DDBBatchWriteItemHighLatency-panther-compliance-ComplianceTable-SuccessfulRequestLatency-Maximum"
+  alarm_description   = "DynamoDB panther-compliance is experiencing high latency BatchWriteItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "SuccessfulRequestLatency"
+  dimensions          = { TableName = "panther-compliance", Operation = "BatchWriteItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 5
+  period              = 60
+  threshold           = 1000
+  unit                = "Milliseconds"
+  statistic           = "Maximum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBBatchWriteItemThrottlepanthercomplianceComplianceTableThrottledRequestsSum" {
+  alarm_name          = "PantherAlarm-DDBBatchWriteItemThrottle-panther-compliance-ComplianceTable-ThrottledRequests-Sum"
+  alarm_description   = "DynamoDB panther-compliance is throttling BatchWriteItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "ThrottledRequests"
+  dimensions          = { TableName = "panther-compliance", Operation = "BatchWriteItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBGetItemErrorpanthercomplianceComplianceTableSystemErrorsSum" {
+  alarm_name          = "PantherAlarm-DDBGetItemError-panther-compliance-ComplianceTable-SystemErrors-Sum"
+  alarm_description   = "DynamoDB panther-compliance is failing GetItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "SystemErrors"
+  dimensions          = { TableName = "panther-compliance", Operation = "GetItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBGetItemHighLatencypanthercomplianceComplianceTableSuccessfulRequestLatencyMaximum" {
+  alarm_name          = "PantherAlarm-DDBGetItemHighLatency-panther-compliance-ComplianceTable-SuccessfulRequestLatency-Maximum"
+  alarm_description   = "DynamoDB panther-compliance is experiencing high latency GetItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "SuccessfulRequestLatency"
+  dimensions          = { TableName = "panther-compliance", Operation = "GetItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 5
+  period              = 60
+  threshold           = 1000
+  unit                = "Milliseconds"
+  statistic           = "Maximum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBGetItemThrottlepanthercomplianceComplianceTableThrottledRequestsSum" {
+  alarm_name          = "PantherAlarm-DDBGetItemThrottle-panther-compliance-ComplianceTable-ThrottledRequests-Sum"
+  alarm_description   = "DynamoDB panther-compliance is throttling GetItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "ThrottledRequests"
+  dimensions          = { TableName = "panther-compliance", Operation = "GetItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBPutItemErrorpanthercomplianceComplianceTableSystemErrorsSum" {
+  alarm_name          = "PantherAlarm-DDBPutItemError-panther-compliance-ComplianceTable-SystemErrors-Sum"
+  alarm_description   = "DynamoDB panther-compliance is failing PutItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "SystemErrors"
+  dimensions          = { TableName = "panther-compliance", Operation = "PutItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBPutItemHighLatencypanthercomplianceComplianceTableSuccessfulRequestLatencyMaximum" {
+  alarm_name          = "PantherAlarm-DDBPutItemHighLatency-panther-compliance-ComplianceTable-SuccessfulRequestLatency-Maximum"
+  alarm_description   = "DynamoDB panther-compliance is experiencing high latency PutItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "SuccessfulRequestLatency"
+  dimensions          = { TableName = "panther-compliance", Operation = "PutItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 5
+  period              = 60
+  threshold           = 1000
+  unit                = "Milliseconds"
+  statistic           = "Maximum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBPutItemThrottlepanthercomplianceComplianceTableThrottledRequestsSum" {
+  alarm_name          = "PantherAlarm-DDBPutItemThrottle-panther-compliance-ComplianceTable-ThrottledRequests-Sum"
+  alarm_description   = "DynamoDB panther-compliance is throttling PutItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "ThrottledRequests"
+  dimensions          = { TableName = "panther-compliance", Operation = "PutItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBReadThrottlepanthercomplianceComplianceTableReadThrottleEventsSum" {
+  alarm_name          = "PantherAlarm-DDBReadThrottle-panther-compliance-ComplianceTable-ReadThrottleEvents-Sum"
+  alarm_description   = "DynamoDB panther-compliance is throttling reads. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "ReadThrottleEvents"
+  dimensions          = { TableName = "panther-compliance" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "Count"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBReadThrottlepanthercompliancepolicyindexComplianceTableReadThrottleEventsSum" {
+  alarm_name          = "PantherAlarm-DDBReadThrottle-panther-compliance-policy-index-ComplianceTable-ReadThrottleEvents-Sum"
+  alarm_description   = "DynamoDB panther-compliance-policy-index is throttling reads. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "ReadThrottleEvents"
+  dimensions          = { TableName = "panther-compliance", GlobalSecondaryIndexName = "policy-index" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "Count"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBScanErrorpanthercomplianceComplianceTableSystemErrorsSum" {
+  alarm_name          = "PantherAlarm-DDBScanError-panther-compliance-ComplianceTable-SystemErrors-Sum"
+  alarm_description   = "DynamoDB panther-compliance is failing Scan operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "SystemErrors"
+  dimensions          = { TableName = "panther-compliance", Operation = "Scan" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBScanHighLatencypanthercomplianceComplianceTableSuccessfulRequestLatencyMaximum" {
+  alarm_name          = "PantherAlarm-DDBScanHighLatency-panther-compliance-ComplianceTable-SuccessfulRequestLatency-Maximum"
+  alarm_description   = "DynamoDB panther-compliance is experiencing high latency Scan operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "SuccessfulRequestLatency"
+  dimensions          = { TableName = "panther-compliance", Operation = "Scan" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 5
+  period              = 60
+  threshold           = 1000
+  unit                = "Milliseconds"
+  statistic           = "Maximum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBScanThrottlepanthercomplianceComplianceTableThrottledRequestsSum" {
+  alarm_name          = "PantherAlarm-DDBScanThrottle-panther-compliance-ComplianceTable-ThrottledRequests-Sum"
+  alarm_description   = "DynamoDB panther-compliance is throttling Scan operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "ThrottledRequests"
+  dimensions          = { TableName = "panther-compliance", Operation = "Scan" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBUpdateItemErrorpanthercomplianceComplianceTableSystemErrorsSum" {
+  alarm_name          = "PantherAlarm-DDBUpdateItemError-panther-compliance-ComplianceTable-SystemErrors-Sum"
+  alarm_description   = "DynamoDB panther-compliance is failing UpdateItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "SystemErrors"
+  dimensions          = { TableName = "panther-compliance", Operation = "UpdateItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBUpdateItemHighLatencypanthercomplianceComplianceTableSuccessfulRequestLatencyMaximum" {
+  alarm_name          = "PantherAlarm-DDBUpdateItemHighLatency-panther-compliance-ComplianceTable-SuccessfulRequestLatency-Maximum"
+  alarm_description   = "DynamoDB panther-compliance is experiencing high latency UpdateItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "SuccessfulRequestLatency"
+  dimensions          = { TableName = "panther-compliance", Operation = "UpdateItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 5
+  period              = 60
+  threshold           = 1000
+  unit                = "Milliseconds"
+  statistic           = "Maximum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBUpdateItemThrottlepanthercomplianceComplianceTableThrottledRequestsSum" {
+  alarm_name          = "PantherAlarm-DDBUpdateItemThrottle-panther-compliance-ComplianceTable-ThrottledRequests-Sum"
+  alarm_description   = "DynamoDB panther-compliance is throttling UpdateItem operations. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "ThrottledRequests"
+  dimensions          = { TableName = "panther-compliance", Operation = "UpdateItem" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBWriteThrottlepanthercomplianceComplianceTableWriteThrottleEventsSum" {
+  alarm_name          = "PantherAlarm-DDBWriteThrottle-panther-compliance-ComplianceTable-WriteThrottleEvents-Sum"
+  alarm_description   = "DynamoDB panther-compliance is throttling writes. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "WriteThrottleEvents"
+  dimensions          = { TableName = "panther-compliance" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "Count"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmDDBWriteThrottlepanthercompliancepolicyindexComplianceTableWriteThrottleEventsSum" {
+  alarm_name          = "PantherAlarm-DDBWriteThrottle-panther-compliance-policy-index-ComplianceTable-WriteThrottleEvents-Sum"
+  alarm_description   = "DynamoDB panther-compliance-policy-index is throttling writes. See: https://docs.runpanther.io/operations/runbooks#panther-compliance"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/DynamoDB"
+  metric_name         = "WriteThrottleEvents"
+  dimensions          = { TableName = "panther-compliance", GlobalSecondaryIndexName = "policy-index" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "Count"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmELBErrortestLoadbalancerPublicLoadBalancerHTTPCodeELB4XXCountSum" {
+  alarm_name          = "PantherAlarm-ELBError-testLoadbalancer-PublicLoadBalancer-HTTPCode_ELB_4XX_Count-Sum"
+  alarm_description   = "ALB testLoadbalancer has elevated ELB 4XX errors. See: https://docs.runpanther.io/operations/runbooks#web"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/ApplicationELB"
+  metric_name         = "HTTPCode_ELB_4XX_Count"
+  dimensions          = { LoadBalancer = "testLoadbalancer" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 20
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmELBHighLatencytestLoadbalancerPublicLoadBalancerTargetResponseLatencyMaximum" {
+  alarm_name          = "PantherAlarm-ELBHighLatency-testLoadbalancer-PublicLoadBalancer-TargetResponseLatency-Maximum"
+  alarm_description   = "ALB testLoadbalancer is experience high latency. See: https://docs.runpanther.io/operations/runbooks#web"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/ApplicationELB"
+  metric_name         = "TargetResponseLatency"
+  dimensions          = { LoadBalancer = "testLoadbalancer" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 5
+  period              = 60
+  threshold           = 1
+  unit                = "Seconds"
+  statistic           = "Maximum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmELBTargetErrortestLoadbalancerPublicLoadBalancerHTTPCodeTarget4XXCountSum" {
+  alarm_name          = "PantherAlarm-ELBTargetError-testLoadbalancer-PublicLoadBalancer-HTTPCode_Target_4XX_Count-Sum"
+  alarm_description   = "ALB testLoadbalancer has elevated Target 4XX errors. See: https://docs.runpanther.io/operations/runbooks#web"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/ApplicationELB"
+  metric_name         = "HTTPCode_Target_4XX_Count"
+  dimensions          = { LoadBalancer = "testLoadbalancer" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 5
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmELBUnhealthytestLoadbalancerPublicLoadBalancerUnHealthyHostCountSum" {
+  alarm_name          = "PantherAlarm-ELBUnhealthy-testLoadbalancer-PublicLoadBalancer-UnHealthyHostCount-Sum"
+  alarm_description   = "ALB testLoadbalancer has unhealthy hosts. See: https://docs.runpanther.io/operations/runbooks#web"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/ApplicationELB"
+  metric_name         = "UnHealthyHostCount"
+  dimensions          = { LoadBalancer = "testLoadbalancer" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmLambdaApplicationErrorstestlambdaFunctiontestlambdaerrorsSum" {
+  alarm_name          = "PantherAlarm-LambdaApplicationErrors-test-lambda-Function-test-lambda-errors-Sum"
+  alarm_description   = "Lambda test-lambda is failing. See: https://docs.runpanther.io/operations/runbooks#test-lambda"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "Panther"
+  metric_name         = "test-lambda-errors"
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmLambdaApplicationPanicstestlambdaFunctiontestlambdapanicsSum" {
+  alarm_name          = "PantherAlarm-LambdaApplicationPanics-test-lambda-Function-test-lambda-panics-Sum"
+  alarm_description   = "Lambda test-lambda is panicking. See: https://docs.runpanther.io/operations/runbooks#test-lambda"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "Panther"
+  metric_name         = "test-lambda-panics"
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmLambdaApplicationWarnstestlambdaFunctiontestlambdawarnsSum" {
+  alarm_name          = "PantherAlarm-LambdaApplicationWarns-test-lambda-Function-test-lambda-warns-Sum"
+  alarm_description   = "Lambda test-lambda is warning. See: https://docs.runpanther.io/operations/runbooks#test-lambda"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "Panther"
+  metric_name         = "test-lambda-warns"
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 5
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmLambdaErrorstestlambdaFunctionErrorsSum" {
+  alarm_name          = "PantherAlarm-LambdaErrors-test-lambda-Function-Errors-Sum"
+  alarm_description   = "Lambda test-lambda is failing. See: https://docs.runpanther.io/operations/runbooks#test-lambda"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/Lambda"
+  metric_name         = "Errors"
+  dimensions          = { FunctionName = "test-lambda" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "Count"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmLambdaHighExecutionTimeWarntestlambdaFunctionDurationMaximum" {
+  alarm_name          = "PantherAlarm-LambdaHighExecutionTimeWarn-test-lambda-Function-Duration-Maximum"
+  alarm_description   = "Lambda test-lambda is using more than 90% of available execution time (60000msec). See: https://docs.runpanther.io/operations/runbooks#test-lambda"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/Lambda"
+  metric_name         = "Duration"
+  dimensions          = { FunctionName = "test-lambda" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 3
+  period              = 300
+  threshold           = 54000
+  unit                = "Milliseconds"
+  statistic           = "Maximum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmLambdaHighMemoryWarntestlambdaFunctiontestlambdamemoryMaximum" {
+  alarm_name          = "PantherAlarm-LambdaHighMemoryWarn-test-lambda-Function-test-lambda-memory-Maximum"
+  alarm_description   = "Lambda test-lambda is using more than 90% of available memory (128MB). See: https://docs.runpanther.io/operations/runbooks#test-lambda"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "Panther"
+  metric_name         = "test-lambda-memory"
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 3
+  period              = 300
+  threshold           = 115.2
+  unit                = "None"
+  statistic           = "Maximum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmLambdaThrottlestestlambdaFunctionThrottlesSum" {
+  alarm_name          = "PantherAlarm-LambdaThrottles-test-lambda-Function-Throttles-Sum"
+  alarm_description   = "Lambda test-lambda is being throttled. See: https://docs.runpanther.io/operations/runbooks#test-lambda"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/Lambda"
+  metric_name         = "Throttles"
+  dimensions          = { FunctionName = "test-lambda" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 5
+  unit                = "Count"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmSNSErrortestnotificationsNotificationsNumberOfNotificationsFailedSum" {
+  alarm_name          = "PantherAlarm-SNSError-test-notifications-Notifications-NumberOfNotificationsFailed-Sum"
+  alarm_description   = "SNS topic test-notifications is failing. See: https://docs.runpanther.io/operations/runbooks#test-notifications"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/SNS"
+  metric_name         = "NumberOfNotificationsFailed"
+  dimensions          = { TopicName = "test-notifications" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "Count"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmSNSFilteredOutInvalidAttributestestnotificationsNotificationsNumberOfNotificationsFilteredOutInvalidAttributesSum" {
+  alarm_name          = "PantherAlarm-SNSFilteredOutInvalidAttributes-test-notifications-Notifications-NumberOfNotificationsFilteredOut-InvalidAttributes-Sum"
+  alarm_description   = "SNS topic test-notifications is dropping messages with invalid attributes. See: https://docs.runpanther.io/operations/runbooks#test-notifications"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/SNS"
+  metric_name         = "NumberOfNotificationsFilteredOut-InvalidAttributes"
+  dimensions          = { TopicName = "test-notifications" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "Count"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmSQSDeadLetterstestsqsdlqDeadLetterQueueApproximateNumberOfMessagesVisibleSum" {
+  alarm_name          = "PantherAlarm-SQSDeadLetters-test-sqs-dlq-DeadLetterQueue-ApproximateNumberOfMessagesVisible-Sum"
+  alarm_description   = "SQS queue test-sqs-dlq has failed items from test-sqs. See: https://docs.runpanther.io/operations/runbooks#test-sqs-dlq"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/SQS"
+  metric_name         = "ApproximateNumberOfMessagesVisible"
+  dimensions          = { QueueName = "test-sqs-dlq" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 0
+  unit                = "None"
+  statistic           = "Sum"
+}
+
+resource "aws_cloudwatch_metric_alarm" "PantherAlarmSQSTooOldtestsqsQueueApproximateAgeOfOldestMessageMaximum" {
+  alarm_name          = "PantherAlarm-SQSTooOld-test-sqs-Queue-ApproximateAgeOfOldestMessage-Maximum"
+  alarm_description   = "SQS queue test-sqs has items not being processed at the expected rate. See: https://docs.runpanther.io/operations/runbooks#test-sqs"
+  alarm_actions       = ["my-sns-topic-arn"]
+  treat_missing_data  = "notBreaching"
+  namespace           = "AWS/SQS"
+  metric_name         = "ApproximateAgeOfOldestMessage"
+  dimensions          = { QueueName = "test-sqs" }
+  comparison_operator = "GreaterThanThreshold"
+  evaluation_periods  = 1
+  period              = 300
+  threshold           = 300
+  unit                = "Seconds"
+  statistic           = "Maximum"
+}
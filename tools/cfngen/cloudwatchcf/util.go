@@ -21,6 +21,9 @@ package cloudwatchcf
 import (
 	"fmt"
 	"strconv"
+	"strings"
+
+	"github.com/panther-labs/panther/tools/cfngen"
 )
 
 func getResourceFloat32Property(key string, resource map[interface{}]interface{}) float32 {
@@ -32,6 +35,14 @@ func getResourceFloat32Property(key string, resource map[interface{}]interface{}
 	return (float32)(floatVal)
 }
 
+// getResourceFloat32PropertyDefault is getResourceFloat32Property with defaultVal when the property is not set
+func getResourceFloat32PropertyDefault(key string, defaultVal float32, resource map[interface{}]interface{}) float32 {
+	if getResourceNestedProperty(resource, key) == nil {
+		return defaultVal
+	}
+	return getResourceFloat32Property(key, resource)
+}
+
 func getResourceProperty(key string, resource map[interface{}]interface{}) string {
 	switch props := resource[(interface{})("Properties")].(type) {
 	case map[interface{}]interface{}:
@@ -44,3 +55,133 @@ func getResourceProperty(key string, resource map[interface{}]interface{}) strin
 	}
 	panic(fmt.Sprintf("Cannot find name: %s in %#v", key, resource))
 }
+
+// getResourceName returns the name of the resource from the key property and the value to use as a metric dimension.
+// If the property is a CF intrinsic (e.g., Ref, Fn::Sub) the name is only known at deploy time,
+// so the logical id is used as the name and the dimension is the intrinsic for CF to resolve.
+// If the property is absent CF generates the name, which is returned by a Ref to the resource.
+func getResourceName(key, logicalID string, resource map[interface{}]interface{}) (name string, dimensionValue interface{}) {
+	switch props := resource[(interface{})("Properties")].(type) {
+	case map[interface{}]interface{}:
+		switch val := props[(interface{})(key)].(type) {
+		case string:
+			return val, val
+		case int, int32, int64:
+			return fmt.Sprintf("%d", val), fmt.Sprintf("%d", val)
+		case map[interface{}]interface{}:
+			return logicalID, cfIntrinsic(val)
+		case nil:
+			return logicalID, cfngen.Ref{Ref: logicalID}
+		}
+	case nil:
+		return logicalID, cfngen.Ref{Ref: logicalID}
+	}
+	panic(fmt.Sprintf("Cannot find name: %s in %#v", key, resource))
+}
+
+// cfIntrinsic converts the yaml representation of a CF intrinsic function so it can be serialized as JSON
+func cfIntrinsic(yamlObj interface{}) interface{} {
+	switch objVal := yamlObj.(type) {
+	case map[interface{}]interface{}:
+		intrinsic := make(map[string]interface{}, len(objVal))
+		for k, v := range objVal {
+			intrinsic[fmt.Sprintf("%v", k)] = cfIntrinsic(v)
+		}
+		return intrinsic
+	case []interface{}:
+		intrinsic := make([]interface{}, len(objVal))
+		for i := range objVal {
+			intrinsic[i] = cfIntrinsic(objVal[i])
+		}
+		return intrinsic
+	}
+	return yamlObj
+}
+
+// getResourceNestedProperty returns the value at the path of keys under the resource Properties, or nil if not found
+func getResourceNestedProperty(resource map[interface{}]interface{}, keys ...string) interface{} {
+	val := resource[(interface{})("Properties")]
+	for _, key := range keys {
+		props, ok := val.(map[interface{}]interface{})
+		if !ok {
+			return nil
+		}
+		val = props[(interface{})(key)]
+	}
+	return val
+}
+
+// getAttLogicalID returns the logical id referenced by a Fn::GetAtt, a list of the logical id and attribute or the
+// string "LogicalID.Attribute" (short form !GetAtt is expanded to the list as the template is read), or "" if not a
+// Fn::GetAtt. Literal strings are names or arns, even with dots (e.g., panther.alerts).
+func getAttLogicalID(value interface{}) string {
+	if val, isMap := value.(map[interface{}]interface{}); isMap {
+		return getAttValueLogicalID(val["Fn::GetAtt"])
+	}
+	return ""
+}
+
+// getAttValueLogicalID returns the logical id of the value of a Fn::GetAtt, either form, or "" if neither
+func getAttValueLogicalID(getAtt interface{}) string {
+	switch getAtt := getAtt.(type) {
+	case []interface{}:
+		if len(getAtt) > 0 {
+			logicalID, _ := getAtt[0].(string)
+			return logicalID
+		}
+	case string:
+		if dot := strings.Index(getAtt, "."); dot > 0 {
+			return getAtt[:dot]
+		}
+	}
+	return ""
+}
+
+// resourceNameProperties are the properties that name resources, by resource type (e.g., QueueName for SQS queues)
+var resourceNameProperties = []string{"FunctionName", "QueueName", "TableName", "TopicName", "StateMachineName", "Name",
+	"DBInstanceIdentifier", "DBClusterIdentifier", "DeliveryStreamName", "DomainName", "ClientName", "UserPoolName"}
+
+// getLiteralResourceName returns the name of the resource if it is set with a constant value, or ""
+func getLiteralResourceName(resource map[interface{}]interface{}) string {
+	for _, nameProperty := range resourceNameProperties {
+		if name, ok := getResourceNestedProperty(resource, nameProperty).(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// refLogicalID returns the logical id of the resource referenced by a Ref, which may be in the short form (e.g., !Ref X)
+// that is decoded as the string X, or "" if the value is not a Ref to one of the resources
+func refLogicalID(value interface{}, resources map[string]map[interface{}]interface{}) (logicalID string) {
+	switch val := value.(type) {
+	case string:
+		logicalID = val
+	case map[interface{}]interface{}:
+		logicalID, _ = val["Ref"].(string)
+	}
+	if _, found := resources[logicalID]; !found {
+		return ""
+	}
+	return logicalID
+}
+
+// getResourceTags returns the CF tags of the resource, which are a list of Key/Value pairs for most resources
+// but a map for SAM resources
+func getResourceTags(resource map[interface{}]interface{}) (tags map[string]string) {
+	tags = make(map[string]string)
+	switch resourceTags := getResourceNestedProperty(resource, "Tags").(type) {
+	case []interface{}:
+		for _, tag := range resourceTags {
+			if tagMap, ok := tag.(map[interface{}]interface{}); ok {
+				key, _ := tagMap["Key"].(string)
+				tags[key] = fmt.Sprintf("%v", tagMap["Value"])
+			}
+		}
+	case map[interface{}]interface{}:
+		for key, value := range resourceTags {
+			tags[fmt.Sprintf("%v", key)] = fmt.Sprintf("%v", value)
+		}
+	}
+	return tags
+}
@@ -21,9 +21,15 @@ package cloudwatchcf
 import (
 	"io/ioutil"
 	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
-// Read CF
+// when set (e.g., UPDATE_GOLDEN=1 go test ./...) the expected files are regenerated from the output of the tests
+const updateGoldenEnv = "UPDATE_GOLDEN"
+
+// Read CF (JSON or YAML)
 func readTestFile(filename string) ([]byte, error) {
 	fd, err := os.Open(filename)
 	if err != nil {
@@ -33,8 +39,7 @@ func readTestFile(filename string) ([]byte, error) {
 	return ioutil.ReadAll(fd)
 }
 
-// Write CF (used to easily re-create expected test files)
-//nolint:unused,deadcode
+// Write CF in either format (used to re-create expected test files)
 func writeTestFile(cf []byte, filename string) error {
 	fd, err := os.Create(filename)
 	if err != nil {
@@ -44,3 +49,63 @@ func writeTestFile(cf []byte, filename string) error {
 	_, err = fd.Write(cf)
 	return err
 }
+
+// requireGoldenFile compares the generated output to the expected file, rewriting the file instead if UPDATE_GOLDEN is
+// set. A missing expected file fails the test rather than being created, so it is not silently a no-op.
+func requireGoldenFile(t *testing.T, expectedFile string, actual []byte) {
+	t.Helper()
+	if os.Getenv(updateGoldenEnv) != "" {
+		require.NoError(t, writeTestFile(actual, expectedFile))
+		return
+	}
+	expected, err := readTestFile(expectedFile)
+	if os.IsNotExist(err) {
+		require.FailNowf(t, "missing expected file", "%s does not exist, run the test with %s=1 to create it",
+			expectedFile, updateGoldenEnv)
+	}
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
+
+// testStackOutputs are the outputs of the web stack looked up by the test templates (e.g., the load balancer the target
+// groups forward from)
+func testStackOutputs() map[string]string {
+	return map[string]string{
+		"WebApplicationLoadBalancerFullName": "testLoadbalancer",
+		"WebApplicationGraphqlApiId":         "testGraphqlId",
+	}
+}
+
+// requireGoldenAlarms builds the alarms of the CF template in the file with the config, requiring the generated CF to
+// match the expected file and be valid, and the alarms to be valid for the template
+func requireGoldenAlarms(t *testing.T, config *Config, cfFile, expectedFile string) *Template {
+	t.Helper()
+	template, err := BuildAlarms(config, cfFile)
+	require.NoError(t, err)
+	cf, err := template.Marshal(JSONFormat)
+	require.NoError(t, err)
+	requireGoldenFile(t, expectedFile, cf)
+	requireValidCloudFormation(t, cf)
+	require.NoError(t, ValidateAlarms(template.Alarms, cfFile))
+	return template
+}
+
+func TestGetAttLogicalID(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     interface{}
+		logicalID string
+	}{
+		{"List", map[interface{}]interface{}{"Fn::GetAtt": []interface{}{"AlertsQueue", "Arn"}}, "AlertsQueue"},
+		{"String", map[interface{}]interface{}{"Fn::GetAtt": "AlertsQueue.Arn"}, "AlertsQueue"},
+		{"Ref", map[interface{}]interface{}{"Ref": "AlertsQueue"}, ""},
+		{"NameWithDot", "panther.alerts", ""},
+		{"Arn", "arn:aws:sqs:us-east-1:123456789012:panther-alerts", ""},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.logicalID, getAttLogicalID(testCase.value))
+		})
+	}
+}
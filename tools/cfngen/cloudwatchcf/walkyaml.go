@@ -1,9 +1,10 @@
 package cloudwatchcf
 
 import (
-	"io/ioutil"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
@@ -43,47 +44,89 @@ func walkYamlFiles(cfDir string, generatorFunc func(string) error) error {
 	})
 }
 
-type YamlDispatcher func(resourceType string, resource map[interface{}]interface{})
+// StdinPath as a cfDir reads a single CF template from stdin
+const StdinPath = "-"
 
-// walk the tree, apply dispatcher on each resource based on Type
-func walkYamlMap(yamlObj interface{}, dispatcher YamlDispatcher) {
-	switch objVal := yamlObj.(type) {
-	case map[interface{}]interface{}:
-		for k, v := range objVal {
-			switch keyVal := k.(type) {
-			case string:
-				switch keyVal {
-				case "Type":
-					switch valVal := v.(type) {
-					case string:
-						dispatcher(valVal, objVal)
-					}
+type YamlDispatcher func(logicalID, resourceType string, resource map[interface{}]interface{})
+
+// getResources returns the resources declared in the CF keyed by logical id
+func getResources(yamlObj interface{}) (resources map[string]map[interface{}]interface{}) {
+	resources = make(map[string]map[interface{}]interface{})
+	if cf, ok := yamlObj.(map[interface{}]interface{}); ok {
+		if cfResources, ok := cf["Resources"].(map[interface{}]interface{}); ok {
+			for k, v := range cfResources {
+				logicalID, isString := k.(string)
+				resource, isMap := v.(map[interface{}]interface{})
+				if isString && isMap {
+					resources[logicalID] = resource
 				}
-				walkYamlMap(v, dispatcher)
 			}
 		}
-	case []interface{}:
-		for i := range objVal {
-			walkYamlMap(objVal[i], dispatcher)
-		}
 	}
+	return resources
 }
 
-func readYaml(fileName string) (yamlObj interface{}, err error) {
+// cfResources are the resources of a CF template keyed by logical id
+type cfResources map[string]map[interface{}]interface{}
+
+// readYamlResources decodes only the Resources of the CF template in the file
+func readYamlResources(fileName string) (resources cfResources, err error) {
 	fh, err := os.Open(fileName)
 	if err != nil {
 		return nil, errors.Wrap(err, fileName)
 	}
+	defer fh.Close()
 
-	inputCf, err := ioutil.ReadAll(fh)
+	resources, err = decodeYamlResources(fh)
 	if err != nil {
 		return nil, errors.Wrap(err, fileName)
 	}
+	return resources, nil
+}
+
+// decodeYamlResources decodes only the Resources of the CF template, the other sections are not materialized.
+// Short form intrinsics (e.g., !Ref Queue) decode as the long forms (e.g., {"Ref": "Queue"}).
+func decodeYamlResources(reader io.Reader) (resources cfResources, err error) {
+	var cf struct {
+		Resources cfResources `yaml:"Resources"`
+	}
+	err = yaml.NewDecoder(newShortFormReader(reader)).Decode(&cf)
+	if _, isTypeError := err.(*yaml.TypeError); isTypeError {
+		// not a CF template (e.g., a Dockerfile) or some resources are not maps, these have nothing to monitor
+		err = nil
+	}
+	if err != nil && err != io.EOF { // EOF is an empty file
+		return nil, err
+	}
+	return cf.Resources, nil
+}
+
+// walk applies the dispatcher to each resource in a single pass, ordered by logical id so results are deterministic
+func (resources cfResources) walk(dispatcher YamlDispatcher) {
+	logicalIDs := make([]string, 0, len(resources))
+	for logicalID := range resources {
+		logicalIDs = append(logicalIDs, logicalID)
+	}
+	sort.Strings(logicalIDs)
+	for _, logicalID := range logicalIDs {
+		resource := resources[logicalID]
+		if resourceType, ok := resource["Type"].(string); ok {
+			dispatcher(logicalID, resourceType, resource)
+		}
+	}
+}
 
-	err = yaml.Unmarshal(inputCf, &yamlObj)
+func readYaml(fileName string) (yamlObj interface{}, err error) {
+	fh, err := os.Open(fileName)
 	if err != nil {
 		return nil, errors.Wrap(err, fileName)
 	}
+	defer fh.Close()
+
+	err = yaml.NewDecoder(newShortFormReader(fh)).Decode(&yamlObj)
+	if err != nil && err != io.EOF { // EOF is an empty file
+		return nil, errors.Wrap(err, fileName)
+	}
 
 	return yamlObj, nil
 }
@@ -0,0 +1,100 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Warning is a resource of a supported type that has no alarms for a reason other than being excluded (e.g., its
+// name cannot be resolved), so unintended gaps in the monitoring are reported rather than silently skipped
+type Warning struct {
+	File      string // of the template defining the resource
+	LogicalID string
+	Type      string
+	Reason    string
+}
+
+func (warning Warning) String() string {
+	return fmt.Sprintf("%s: %s (%s) has no alarms, %s", warning.File, warning.LogicalID, warning.Type, warning.Reason)
+}
+
+// nameIntrinsics are the CF intrinsics that resolve to a string, so they may name a resource
+var nameIntrinsics = map[string]struct{}{
+	"Ref": {}, "Fn::Sub": {}, "Fn::Join": {}, "Fn::Select": {}, "Fn::ImportValue": {}, "Fn::If": {}, "Fn::FindInMap": {},
+	"Fn::GetAtt": {}, "Fn::Base64": {},
+}
+
+// skippedReason returns why no alarms can be generated for the resource of a supported type, or "" if they can
+func skippedReason(logicalID, resourceType string, resource map[interface{}]interface{},
+	resources map[string]map[interface{}]interface{}, config *Config) string {
+
+	if _, isSupported := knownMetrics[resourceType]; !isSupported {
+		return ""
+	}
+	if reason := unresolvableName(resource); reason != "" {
+		return reason
+	}
+	switch resourceType {
+	case restAPIType:
+		if getResourceNestedProperty(resource, "Name") == nil {
+			// the ApiName dimension requires the name, a Ref to a REST API returns the id
+			return "the REST API has no Name for the ApiName dimension"
+		}
+	case cognitoUserPoolClientType:
+		if _, _, found := cognitoUserPool(getResourceNestedProperty(resource, "UserPoolId"), resources); !found {
+			return "the UserPoolId is not a user pool in the template or a pool id"
+		}
+	case targetGroupType:
+		if targetGroupLoadBalancerDimension(logicalID, resources, config) == nil {
+			return "no load balancer in the template or the stack outputs forwards to the target group"
+		}
+	case eventSourceMappingType:
+		if sourceType, _, _ := eventSource(getResourceNestedProperty(resource, "EventSourceArn"), resources); sourceType == "" {
+			return "the event source is not a known SQS queue or Kinesis stream"
+		}
+	}
+	return ""
+}
+
+// unresolvableName returns why the name of the resource cannot be used for alarm names and dimensions, or "" if it can
+// be or is generated by CF
+func unresolvableName(resource map[interface{}]interface{}) string {
+	for _, nameProperty := range resourceNameProperties {
+		switch name := getResourceNestedProperty(resource, nameProperty).(type) {
+		case nil, string, int, int32, int64:
+		case map[interface{}]interface{}:
+			if len(name) == 0 {
+				return fmt.Sprintf("the %s {} does not resolve to a name", nameProperty)
+			}
+			var functions []string
+			for function := range name {
+				functions = append(functions, fmt.Sprintf("%v", function))
+			}
+			sort.Strings(functions)
+			if _, isName := nameIntrinsics[functions[0]]; len(functions) != 1 || !isName {
+				return fmt.Sprintf("the %s %s does not resolve to a name", nameProperty, strings.Join(functions, ", "))
+			}
+		default:
+			return fmt.Sprintf("the %s %v is not a name", nameProperty, name)
+		}
+	}
+	return ""
+}
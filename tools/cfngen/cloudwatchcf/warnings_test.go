@@ -0,0 +1,81 @@
+package cloudwatchcf
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarnings(t *testing.T) {
+	const templatePath = "./testdata/warnings.yml"
+	template, err := BuildAlarms(NewConfig("my-sns-topic-arn", nil), templatePath)
+	require.NoError(t, err)
+	require.Equal(t, []Warning{
+		{
+			File:      templatePath,
+			LogicalID: "EmptyNameQueue",
+			Type:      "AWS::SQS::Queue",
+			Reason:    "the QueueName {} does not resolve to a name",
+		},
+		{
+			File:      templatePath,
+			LogicalID: "ListFunction",
+			Type:      "AWS::Lambda::Function",
+			Reason:    "the FunctionName [panther list] is not a name",
+		},
+		{
+			File:      templatePath,
+			LogicalID: "RestApi",
+			Type:      restAPIType,
+			Reason:    "the REST API has no Name for the ApiName dimension",
+		},
+		{
+			File:      templatePath,
+			LogicalID: "SplitFunction",
+			Type:      "AWS::Lambda::Function",
+			Reason:    "the FunctionName Fn::Split does not resolve to a name",
+		},
+		{
+			File:      templatePath,
+			LogicalID: "TableEventSource",
+			Type:      eventSourceMappingType,
+			Reason:    "the event source is not a known SQS queue or Kinesis stream",
+		},
+	}, template.Warnings)
+	require.Equal(t, "./testdata/warnings.yml: SplitFunction (AWS::Lambda::Function) has no alarms, "+
+		"the FunctionName Fn::Split does not resolve to a name", template.Warnings[3].String())
+
+	// the resources that can be alarmed are
+	alarmed := make(map[string]struct{})
+	for _, alarm := range template.Alarms {
+		alarmed[alarm.LogicalID] = struct{}{}
+	}
+	require.Equal(t, map[string]struct{}{"Function": {}, "Table": {}}, alarmed)
+
+	// nor are there metric filters for the skipped resources
+	metrics, err := BuildMetrics(NewConfig("", nil), templatePath)
+	require.NoError(t, err)
+	require.NotEmpty(t, metrics.MetricFilters)
+	for _, metricFilter := range metrics.MetricFilters {
+		metricName := metricFilter.Properties.MetricTransformations[0].MetricName
+		require.True(t, strings.HasPrefix(metricName, "Function-"), metricName)
+	}
+}
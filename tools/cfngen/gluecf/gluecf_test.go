@@ -21,7 +21,6 @@ package gluecf
 import (
 	"testing"
 
-	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/panther-labs/panther/api/lambda/core/log_analysis/log_processor/models"
@@ -50,11 +49,5 @@ func TestTablesCloudFormation(t *testing.T) {
 	cf, err := GenerateTables(tables)
 	require.NoError(t, err)
 
-	// uncomment to make a new expected file
-	//writeTestFile(cf, expectedFile)
-
-	expectedOutput, err := readTestFile(expectedFile)
-	require.NoError(t, err)
-
-	assert.Equal(t, expectedOutput, cf)
+	requireGoldenFile(t, expectedFile, cf)
 }
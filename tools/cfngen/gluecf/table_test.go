@@ -21,7 +21,6 @@ package gluecf
 import (
 	"testing"
 
-	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/panther-labs/panther/tools/cfngen"
@@ -93,11 +92,5 @@ func TestTables(t *testing.T) {
 	cf, err := cfTemplate.CloudFormation()
 	require.NoError(t, err)
 
-	// uncomment to make a new expected file
-	// writeTestFile(cf, expectedFile)
-
-	expectedOutput, err := readTestFile(expectedFile)
-	require.NoError(t, err)
-
-	assert.Equal(t, expectedOutput, cf)
+	requireGoldenFile(t, expectedFile, cf)
 }
@@ -23,8 +23,14 @@ package gluecf
 import (
 	"io/ioutil"
 	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
+// when set (e.g., UPDATE_GOLDEN=1 go test ./...) the expected files are regenerated from the output of the tests
+const updateGoldenEnv = "UPDATE_GOLDEN"
+
 // Read CF
 func readTestFile(filename string) ([]byte, error) {
 	fd, err := os.Open(filename)
@@ -35,8 +41,7 @@ func readTestFile(filename string) ([]byte, error) {
 	return ioutil.ReadAll(fd)
 }
 
-// Write CF (used to easily re-create expected test files)
-//nolint:unused,deadcode
+// Write CF (used to re-create expected test files)
 func writeTestFile(cf []byte, filename string) error {
 	fd, err := os.Create(filename)
 	if err != nil {
@@ -46,3 +51,20 @@ func writeTestFile(cf []byte, filename string) error {
 	_, err = fd.Write(cf)
 	return err
 }
+
+// requireGoldenFile compares the generated output to the expected file, rewriting the file instead if UPDATE_GOLDEN is
+// set. A missing expected file fails the test rather than being created, so it is not silently a no-op.
+func requireGoldenFile(t *testing.T, expectedFile string, actual []byte) {
+	t.Helper()
+	if os.Getenv(updateGoldenEnv) != "" {
+		require.NoError(t, writeTestFile(actual, expectedFile))
+		return
+	}
+	expected, err := readTestFile(expectedFile)
+	if os.IsNotExist(err) {
+		require.FailNowf(t, "missing expected file", "%s does not exist, run the test with %s=1 to create it",
+			expectedFile, updateGoldenEnv)
+	}
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+}
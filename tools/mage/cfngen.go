@@ -112,7 +112,7 @@ const alarmStackResourceTemplate = `
 `
 
 // Generate CloudWatch alarms as CloudFormation
-func generateAlarms(snsTopicArn string, stackOutputs map[string]string) error {
+func generateAlarms(alarmConfig *cloudwatchcf.Config) error {
 	var alarms []*cloudwatchcf.Alarm
 
 	outDir := filepath.Join("out", "deployments", "cloudwatch")
@@ -144,10 +144,24 @@ func generateAlarms(snsTopicArn string, stackOutputs map[string]string) error {
 			return fmt.Errorf("failed to write file %s: %v", masterAlarmsCfFileName, err)
 		}
 		// generate alarms
-		fileAlarms, cf, err := cloudwatchcf.GenerateAlarms(snsTopicArn, stackOutputs, cfDir)
+		template, err := cloudwatchcf.BuildAlarms(alarmConfig, cfDir)
 		if err != nil {
 			return fmt.Errorf("failed to generate alarms CloudFormation template %s: %v", alarmsCfFilePath, err)
 		}
+		for _, warning := range template.Warnings {
+			logger.Warn(warning)
+		}
+		cf, err := template.Marshal(cloudwatchcf.JSONFormat)
+		if err != nil {
+			return fmt.Errorf("failed to generate alarms CloudFormation template %s: %v", alarmsCfFilePath, err)
+		}
+		fileAlarms := template.Alarms
+		if err = cloudwatchcf.ValidateAlarms(fileAlarms, cfDir); err != nil {
+			return fmt.Errorf("failed to validate alarms CloudFormation template %s: %v", alarmsCfFilePath, err)
+		}
+		if summary, err := cloudwatchcf.SummarizeAlarms(fileAlarms, cfDir); err == nil {
+			logger.Debugf("alarm coverage for %s:\n%s", cfDir, summary)
+		}
 		alarms = append(alarms, fileAlarms...) // save for validation
 
 		// write cf to file referenced in master template
@@ -162,21 +176,27 @@ func generateAlarms(snsTopicArn string, stackOutputs map[string]string) error {
 	}
 
 	// confirm all alarms generated are documented by cfndoc tags
-	resourceLookup := resourceDocumentation()
-	failedValidation := false
-	for _, alarm := range alarms {
-		if _, found := resourceLookup[alarm.Resource]; !found {
-			logger.Errorf("resource %s is missing cfndoc for alarm %s", alarm.Resource, alarm.Properties.AlarmName)
-			failedValidation = true
-		}
+	undocumented := undocumentedAlarms(alarms, resourceDocumentation())
+	for _, alarm := range undocumented {
+		logger.Errorf("resource %s is missing cfndoc for alarm %s", alarm.Resource, alarm.Properties.AlarmName)
 	}
-	if failedValidation {
+	if len(undocumented) > 0 {
 		logger.Fatal("all alarms must be documented")
 	}
 
 	return nil
 }
 
+// undocumentedAlarms returns the alarms whose resources have no cfndoc documentation in the resourceLookup
+func undocumentedAlarms(alarms []*cloudwatchcf.Alarm, resourceLookup map[string]struct{}) (undocumented []*cloudwatchcf.Alarm) {
+	for _, alarm := range alarms {
+		if _, found := resourceLookup[alarm.Resource]; !found {
+			undocumented = append(undocumented, alarm)
+		}
+	}
+	return undocumented
+}
+
 // return a map to look up if a resource has associated cfndoc documentation
 func resourceDocumentation() (resourceLookup map[string]struct{}) {
 	docs, err := cfndoc.ReadDirs(cfDirs...)
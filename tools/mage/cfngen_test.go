@@ -0,0 +1,58 @@
+package mage
+
+/**
+ * Panther is a scalable, powerful, cloud-native SIEM written in Golang/React.
+ * Copyright (C) 2020 Panther Labs Inc
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ */
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/panther-labs/panther/tools/cfngen/cloudwatchcf"
+)
+
+// the alarms generated for the deployments must all be documented or the deploy fails (see generateAlarms)
+func TestAlarmsDocumented(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir("../..")) // the cfDirs are relative to the repo root
+	defer func() {
+		require.NoError(t, os.Chdir(wd))
+	}()
+
+	backendOutputs := map[string]string{
+		"WebApplicationGraphqlApiId":         "graphql-api-id",
+		"WebApplicationLoadBalancerFullName": "app/web/50dc6c495c0c9188",
+	}
+	config := cloudwatchcf.NewConfig("arn:aws:sns:us-east-1:123456789012:panther-alarms", backendOutputs)
+	var alarms []*cloudwatchcf.Alarm
+	for _, cfDir := range cfDirs {
+		template, err := cloudwatchcf.BuildAlarms(config, cfDir)
+		require.NoError(t, err)
+		alarms = append(alarms, template.Alarms...)
+	}
+	require.NotEmpty(t, alarms)
+
+	var undocumented []string
+	for _, alarm := range undocumentedAlarms(alarms, resourceDocumentation()) {
+		undocumented = append(undocumented, fmt.Sprintf("%s (%s)", alarm.Resource, alarm.Properties.AlarmName))
+	}
+	require.Empty(t, undocumented)
+}
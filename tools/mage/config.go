@@ -39,7 +39,8 @@ type frontendParameters struct {
 }
 
 type monitoringParameters struct {
-	AlarmSNSTopicARN string `yaml:"AlarmSNSTopicARN"` // where to send alarms (optional)
+	AlarmSNSTopicARN   string `yaml:"AlarmSNSTopicARN"`   // where to send alarms (optional)
+	AlarmOverridesFile string `yaml:"AlarmOverridesFile"` // replace default alarm thresholds (optional)
 }
 
 // PantherConfig describes the panther_config.yml file.
@@ -21,6 +21,8 @@ package mage
 import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
+
+	"github.com/panther-labs/panther/tools/cfngen/cloudwatchcf"
 )
 
 const (
@@ -42,7 +44,12 @@ func deployMonitoring(awsSession *session.Session, bucket string, backendOutputs
 	if alarmsSNSTopicARN == "" { // if not set, default to Panther created topic
 		alarmsSNSTopicARN = backendOutputs["AlarmsSNSTopic"]
 	}
-	if err := generateAlarms(alarmsSNSTopicARN, backendOutputs); err != nil {
+	alarmOverrides, err := cloudwatchcf.ReadOverrides(config.MonitoringParameterValues.AlarmOverridesFile)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	alarmConfig := cloudwatchcf.NewConfig(alarmsSNSTopicARN, backendOutputs).Overrides(alarmOverrides)
+	if err := generateAlarms(alarmConfig); err != nil {
 		logger.Fatal(err)
 	}
 
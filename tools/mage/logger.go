@@ -53,4 +53,5 @@ func init() {
 		log.Fatalf("failed to build logger: %s", err)
 	}
 	logger = rawLogger.Sugar()
+	zap.ReplaceGlobals(rawLogger) // so warnings logged by the tools packages are shown
 }